@@ -1,6 +1,7 @@
 package main
 
 import (
+    "context"
     "log"
     "net/http"
     "os"
@@ -9,12 +10,25 @@ import (
     "syscall"
 
     "pac-lead-agent/internal/config"
+    "pac-lead-agent/internal/flow"
     "pac-lead-agent/internal/httpapi"
+    "pac-lead-agent/internal/leads"
+    "pac-lead-agent/internal/transcript"
 )
 
 func main() {
     cfg := config.Load()
 
+    // banco de transcrições configurado e indisponível impede a subida
+    if err := transcript.Init(); err != nil {
+        log.Fatalln(err)
+    }
+
+    // agendador de follow-ups e campanhas (lease por job: seguro com várias réplicas)
+    bg, stopBg := context.WithCancel(context.Background())
+    defer stopBg()
+    flow.StartScheduler(bg, cfg)
+
     mux := http.NewServeMux()
     httpapi.RegisterRoutes(mux, cfg)
 
@@ -44,6 +58,9 @@ func main() {
     case <-quit:
         log.Println("shutting down...")
         _ = srv.Close()
+        stopBg()
+        // grava atualizações de lead ainda agrupadas
+        leads.Default().Flush()
     case err := <-errCh:
         if err != nil {
             log.Println("server error:", err)
@@ -0,0 +1,247 @@
+// Package campaign modela campanhas de mensagens ativas (promoções, leads de
+// anúncios): destinatários, template com variáveis e progresso de envio. O
+// envio em si fica no flow; aqui ficam persistência, render e throttling.
+package campaign
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Status da campanha e de cada destinatário.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusDone      = "done"
+	StatusCancelled = "cancelled"
+
+	StatusPending = "pending"
+	StatusSent    = "sent"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+)
+
+// retention mantém campanhas consultáveis por 30 dias.
+const retention = 30 * 24 * time.Hour
+
+var (
+	ErrNotFound       = errors.New("campanha não encontrada")
+	ErrNoRecipients   = errors.New("campanha sem destinatários")
+	ErrEmptyTemplate  = errors.New("campanha sem mensagem")
+	ErrNoInstance     = errors.New("campanha sem instance_id")
+	ErrTooManyTargets = errors.New("destinatários acima do limite")
+)
+
+// MaxRecipients limita o tamanho de uma campanha (uma requisição).
+const MaxRecipients = 5000
+
+type Recipient struct {
+	Number string            `json:"number"`
+	Name   string            `json:"name,omitempty"`
+	Vars   map[string]string `json:"vars,omitempty"`
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	SentAt *time.Time        `json:"sent_at,omitempty"`
+}
+
+type Campaign struct {
+	ID         string `json:"id"`
+	Name       string `json:"name,omitempty"`
+	OrgID      string `json:"org_id,omitempty"`
+	FlowID     string `json:"flow_id,omitempty"`
+	InstanceID string `json:"instance_id"`
+	// InstanceToken só existe em memória (vindo da requisição); o registro
+	// guarda apenas se havia token, resolvido pelo id na hora do envio.
+	InstanceToken string      `json:"-"`
+	HasToken      bool        `json:"instance_auth,omitempty"`
+	Template      string      `json:"template"`
+	ProductIDs    []string    `json:"product_ids,omitempty"`
+	Recipients    []Recipient `json:"recipients"`
+	// Intervalo aleatório entre envios da mesma instância, em segundos.
+	MinInterval int       `json:"min_interval_seconds"`
+	MaxInterval int       `json:"max_interval_seconds"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Progress resume a campanha para os endpoints de status.
+type Progress struct {
+	Total   int `json:"total"`
+	Pending int `json:"pending"`
+	Sent    int `json:"sent"`
+	Failed  int `json:"failed"`
+	Skipped int `json:"skipped"`
+}
+
+func (c *Campaign) Progress() Progress {
+	p := Progress{Total: len(c.Recipients)}
+	for _, r := range c.Recipients {
+		switch r.Status {
+		case StatusSent:
+			p.Sent++
+		case StatusFailed:
+			p.Failed++
+		case StatusSkipped:
+			p.Skipped++
+		default:
+			p.Pending++
+		}
+	}
+	return p
+}
+
+// Next devolve o índice do próximo destinatário pendente (-1 se acabou).
+func (c *Campaign) Next() int {
+	for i, r := range c.Recipients {
+		if r.Status == "" || r.Status == StatusPending {
+			return i
+		}
+	}
+	return -1
+}
+
+// Validate confere e normaliza a campanha antes de enfileirar.
+func (c *Campaign) Validate() error {
+	if strings.TrimSpace(c.Template) == "" {
+		return ErrEmptyTemplate
+	}
+	if strings.TrimSpace(c.InstanceID) == "" {
+		return ErrNoInstance
+	}
+	c.HasToken = strings.TrimSpace(c.InstanceToken) != ""
+	if len(c.Recipients) == 0 {
+		return ErrNoRecipients
+	}
+	if len(c.Recipients) > MaxRecipients {
+		return ErrTooManyTargets
+	}
+	if c.MinInterval <= 0 {
+		c.MinInterval = 8
+	}
+	if c.MaxInterval < c.MinInterval {
+		c.MaxInterval = c.MinInterval + 12
+	}
+	for i := range c.Recipients {
+		c.Recipients[i].Status = StatusPending
+	}
+	return nil
+}
+
+var varPattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// Render substitui {{variavel}} pelos valores do destinatário. {{nome}}/{{name}}
+// caem no nome do destinatário; variáveis ausentes viram texto vazio.
+func Render(template string, r Recipient) string {
+	out := varPattern.ReplaceAllStringFunc(template, func(m string) string {
+		k := strings.ToLower(varPattern.FindStringSubmatch(m)[1])
+		if v, ok := r.Vars[k]; ok {
+			return v
+		}
+		for vk, v := range r.Vars {
+			if strings.EqualFold(vk, k) {
+				return v
+			}
+		}
+		if k == "nome" || k == "name" {
+			return firstName(r.Name)
+		}
+		return ""
+	})
+	return strings.TrimSpace(out)
+}
+
+func firstName(name string) string {
+	if f := strings.Fields(name); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+func key(id string) string {
+	return "campaign:" + id
+}
+
+// cancelKey guarda o pedido de cancelamento fora do registro da campanha
+// (prefixo diferente de "campaign:", para não aparecer em List).
+func cancelKey(id string) string {
+	return "campaign-cancel:" + id
+}
+
+// New atribui ID e datas a uma campanha validada.
+func New(c Campaign) Campaign {
+	var b [6]byte
+	_, _ = rand.Read(b[:])
+	c.ID = "CMP-" + hex.EncodeToString(b[:])
+	c.Status = StatusQueued
+	c.CreatedAt = time.Now().UTC()
+	c.UpdatedAt = c.CreatedAt
+	return c
+}
+
+// Save persiste a campanha (sem o token da instância).
+func Save(ctx context.Context, st state.Store, c Campaign) error {
+	c.UpdatedAt = time.Now().UTC()
+	return st.Set(ctx, key(c.ID), c, retention)
+}
+
+// Load lê a campanha persistida. Com cancelamento pedido, o status é
+// "cancelled" mesmo que o registro tenha sido regravado depois.
+func Load(ctx context.Context, st state.Store, id string) (Campaign, error) {
+	var c Campaign
+	ok, err := st.Get(ctx, key(id), &c)
+	if err != nil {
+		return Campaign{}, err
+	}
+	if !ok {
+		return Campaign{}, ErrNotFound
+	}
+	if c.Status != StatusDone {
+		cancelled, err := Cancelled(ctx, st, id)
+		if err != nil {
+			return Campaign{}, err
+		}
+		if cancelled {
+			c.Status = StatusCancelled
+		}
+	}
+	return c, nil
+}
+
+// Cancel marca a campanha como cancelada. A marca tem chave própria, gravada
+// uma única vez: não depende de ler e regravar o registro, que o envio
+// atualiza a cada destinatário.
+func Cancel(ctx context.Context, st state.Store, id string) error {
+	_, err := st.SetNX(ctx, cancelKey(id), time.Now().UTC(), retention)
+	return err
+}
+
+// Cancelled informa se o cancelamento foi pedido.
+func Cancelled(ctx context.Context, st state.Store, id string) (bool, error) {
+	var at time.Time
+	return st.Get(ctx, cancelKey(id), &at)
+}
+
+// List devolve as campanhas de uma organização (todas se orgID vazio).
+func List(ctx context.Context, st state.Store, orgID string) ([]Campaign, error) {
+	keys, err := st.Keys(ctx, "campaign:")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Campaign, 0, len(keys))
+	for _, k := range keys {
+		c, err := Load(ctx, st, strings.TrimPrefix(k, "campaign:"))
+		if err != nil || (orgID != "" && c.OrgID != orgID) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out, nil
+}
@@ -0,0 +1,34 @@
+package campaign
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Throttle espera a vez da instância: no máximo um envio de campanha a cada
+// intervalo aleatório entre min e max. O controle fica no state.Store, então
+// vale entre campanhas simultâneas e entre réplicas.
+func Throttle(ctx context.Context, st state.Store, instance string, min, max time.Duration) error {
+	gap := min
+	if max > min {
+		gap += time.Duration(rand.Int63n(int64(max - min)))
+	}
+	k := "throttle:campaign:" + instance
+	for {
+		ok, err := st.SetNX(ctx, k, time.Now(), gap)
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
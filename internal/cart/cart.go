@@ -0,0 +1,147 @@
+// Package cart modela o carrinho de uma conversa e o rascunho de pedido gerado
+// quando o lead confirma a compra.
+package cart
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/money"
+)
+
+// Item é um produto no carrinho com o preço capturado do catálogo no momento da escolha.
+type Item struct {
+	ProductID string       `json:"product_id"`
+	SKU       string       `json:"sku,omitempty"`
+	Name      string       `json:"name"`
+	Variant   string       `json:"variant,omitempty"`
+	Quantity  int          `json:"quantity"`
+	UnitPrice money.Amount `json:"unit_price"`
+	WeightKg  float64      `json:"weight_kg,omitempty"`
+}
+
+// Total do item (preço unitário × quantidade).
+func (i Item) Total() money.Amount { return i.UnitPrice * money.Amount(i.Quantity) }
+
+// key identifica a linha do carrinho: mesmo produto em variantes diferentes são linhas distintas.
+func (i Item) key() string { return i.ProductID + "\x00" + i.Variant }
+
+// Shipping é a opção de frete escolhida pelo lead.
+type Shipping struct {
+	Name     string       `json:"name"`
+	CEP      string       `json:"cep"`
+	Price    money.Amount `json:"price"`
+	Delivery string       `json:"delivery,omitempty"`
+}
+
+// Cart é o carrinho da conversa.
+type Cart struct {
+	Items     []Item    `json:"items,omitempty"`
+	Shipping  *Shipping `json:"shipping,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+// Add soma a quantidade se a linha (produto + variante) já existir. O frete
+// cotado deixa de valer (peso e valor mudaram).
+func (c *Cart) Add(item Item) {
+	if item.Quantity <= 0 {
+		item.Quantity = 1
+	}
+	c.UpdatedAt = time.Now()
+	c.Shipping = nil
+	for i := range c.Items {
+		if c.Items[i].key() == item.key() {
+			c.Items[i].Quantity += item.Quantity
+			c.Items[i].UnitPrice = item.UnitPrice
+			return
+		}
+	}
+	c.Items = append(c.Items, item)
+}
+
+// Remove retira qty unidades (qty <= 0 remove a linha inteira). Variante vazia
+// casa com qualquer variante do produto. Retorna false se nada foi removido.
+// Como em Add, o frete cotado é descartado.
+func (c *Cart) Remove(productID, variant string, qty int) bool {
+	for i := range c.Items {
+		it := &c.Items[i]
+		if it.ProductID != productID || (variant != "" && it.Variant != variant) {
+			continue
+		}
+		c.UpdatedAt = time.Now()
+		c.Shipping = nil
+		if qty > 0 && qty < it.Quantity {
+			it.Quantity -= qty
+			return true
+		}
+		c.Items = append(c.Items[:i], c.Items[i+1:]...)
+		return true
+	}
+	return false
+}
+
+func (c *Cart) Clear() {
+	c.Items = nil
+	c.Shipping = nil
+	c.UpdatedAt = time.Now()
+}
+
+func (c Cart) Empty() bool { return len(c.Items) == 0 }
+
+// Subtotal soma os itens.
+func (c Cart) Subtotal() money.Amount {
+	var t money.Amount
+	for _, it := range c.Items {
+		t += it.Total()
+	}
+	return t
+}
+
+// WeightKg soma o peso dos itens (itens sem peso contam como zero).
+func (c Cart) WeightKg() float64 {
+	var w float64
+	for _, it := range c.Items {
+		w += it.WeightKg * float64(it.Quantity)
+	}
+	return w
+}
+
+// Total do pedido, incluindo o frete escolhido.
+func (c Cart) Total() money.Amount {
+	t := c.Subtotal()
+	if c.Shipping != nil {
+		t += c.Shipping.Price
+	}
+	return t
+}
+
+// Summary renderiza o carrinho no formato do WhatsApp (*negrito*, listas).
+func (c Cart) Summary() string {
+	if c.Empty() {
+		return "🛒 Seu carrinho está vazio."
+	}
+	var b strings.Builder
+	b.WriteString("🛒 *Resumo do pedido*\n\n")
+	for _, it := range c.Items {
+		name := it.Name
+		if it.Variant != "" {
+			name += " (" + it.Variant + ")"
+		}
+		fmt.Fprintf(&b, "• %dx %s — %s\n", it.Quantity, name, it.Total())
+	}
+	fmt.Fprintf(&b, "\nSubtotal: %s\n", c.Subtotal())
+	if sh := c.Shipping; sh != nil {
+		price := sh.Price.String()
+		if sh.Price == 0 {
+			price = "grátis"
+		}
+		fmt.Fprintf(&b, "Frete (%s", sh.Name)
+		if sh.Delivery != "" {
+			fmt.Fprintf(&b, ", %s", sh.Delivery)
+		}
+		fmt.Fprintf(&b, "): %s\n", price)
+	}
+	fmt.Fprintf(&b, "*Total: %s*", c.Total())
+	return b.String()
+}
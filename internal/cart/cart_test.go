@@ -0,0 +1,166 @@
+package cart
+
+import (
+	"strings"
+	"testing"
+
+	"pac-lead-agent/internal/money"
+)
+
+func TestCartTotals(t *testing.T) {
+	anel := Item{ProductID: "1", Name: "Anel", Variant: "Aro 16", UnitPrice: 129990}
+	colar := Item{ProductID: "2", Name: "Colar", UnitPrice: 4990}
+	cases := []struct {
+		name     string
+		ops      func(c *Cart)
+		lines    int
+		subtotal money.Amount
+	}{
+		{"vazio", func(c *Cart) {}, 0, 0},
+		{"quantidade zero vira 1", func(c *Cart) { c.Add(anel) }, 1, 129990},
+		{"mesma linha soma", func(c *Cart) {
+			c.Add(with(colar, 2))
+			c.Add(with(colar, 3))
+		}, 1, 5 * 4990},
+		{"variantes são linhas distintas", func(c *Cart) {
+			c.Add(anel)
+			c.Add(Item{ProductID: "1", Name: "Anel", Variant: "Aro 18", UnitPrice: 129990})
+		}, 2, 2 * 129990},
+		{"preço atualizado vale para a linha toda", func(c *Cart) {
+			c.Add(with(colar, 2))
+			c.Add(Item{ProductID: "2", Name: "Colar", Quantity: 1, UnitPrice: 3990})
+		}, 1, 3 * 3990},
+		{"remove parcial", func(c *Cart) {
+			c.Add(with(colar, 3))
+			c.Remove("2", "", 2)
+		}, 1, 4990},
+		{"remove a linha quando qty cobre tudo", func(c *Cart) {
+			c.Add(with(colar, 3))
+			c.Add(anel)
+			c.Remove("2", "", 5)
+		}, 1, 129990},
+		{"remove sem qty tira a linha", func(c *Cart) {
+			c.Add(anel)
+			c.Add(colar)
+			c.Remove("1", "Aro 16", 0)
+		}, 1, 4990},
+		{"clear", func(c *Cart) {
+			c.Add(anel)
+			c.Clear()
+		}, 0, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var ct Cart
+			c.ops(&ct)
+			if len(ct.Items) != c.lines {
+				t.Errorf("linhas = %d, want %d", len(ct.Items), c.lines)
+			}
+			if got := ct.Subtotal(); got != c.subtotal {
+				t.Errorf("Subtotal = %d, want %d", got, c.subtotal)
+			}
+			if got := ct.Total(); got != c.subtotal {
+				t.Errorf("Total = %d, want %d", got, c.subtotal)
+			}
+			if ct.Empty() != (c.lines == 0) {
+				t.Errorf("Empty = %v com %d linhas", ct.Empty(), c.lines)
+			}
+		})
+	}
+}
+
+func TestCartRemoveMissing(t *testing.T) {
+	var c Cart
+	c.Add(Item{ProductID: "1", Variant: "Aro 16", UnitPrice: 100})
+	if c.Remove("1", "Aro 18", 0) {
+		t.Error("Remove de variante ausente retornou true")
+	}
+	if c.Remove("9", "", 0) {
+		t.Error("Remove de produto ausente retornou true")
+	}
+	if len(c.Items) != 1 {
+		t.Errorf("linhas = %d, want 1", len(c.Items))
+	}
+}
+
+func TestCartSummary(t *testing.T) {
+	var c Cart
+	if got := c.Summary(); got != "🛒 Seu carrinho está vazio." {
+		t.Errorf("Summary vazio = %q", got)
+	}
+	c.Add(Item{ProductID: "1", Name: "Anel", Variant: "Aro 16", Quantity: 2, UnitPrice: 10000})
+	want := "🛒 *Resumo do pedido*\n\n" +
+		"• 2x Anel (Aro 16) — R$ 200,00\n" +
+		"\nSubtotal: R$ 200,00\n" +
+		"*Total: R$ 200,00*"
+	if got := c.Summary(); got != want {
+		t.Errorf("Summary =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func with(it Item, qty int) Item {
+	it.Quantity = qty
+	return it
+}
+
+func TestCartShipping(t *testing.T) {
+	var c Cart
+	c.Add(Item{ProductID: "1", Name: "Anel", Quantity: 2, UnitPrice: 10000, WeightKg: 0.25})
+	c.Add(Item{ProductID: "2", Name: "Caixa", UnitPrice: 500})
+	if got := c.WeightKg(); got != 0.5 {
+		t.Errorf("WeightKg = %v, want 0.5", got)
+	}
+	cases := []struct {
+		name    string
+		ship    *Shipping
+		total   money.Amount
+		summary string
+	}{
+		{"sem frete", nil, 20500, "\nSubtotal: R$ 205,00\n*Total: R$ 205,00*"},
+		{"frete pago", &Shipping{Name: "PAC", Price: 2590, Delivery: "5 a 10 dias úteis"}, 23090,
+			"\nSubtotal: R$ 205,00\nFrete (PAC, 5 a 10 dias úteis): R$ 25,90\n*Total: R$ 230,90*"},
+		{"frete grátis", &Shipping{Name: "Motoboy"}, 20500,
+			"\nSubtotal: R$ 205,00\nFrete (Motoboy): grátis\n*Total: R$ 205,00*"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c.Shipping = tc.ship
+			if got := c.Total(); got != tc.total {
+				t.Errorf("Total = %d, want %d", got, tc.total)
+			}
+			if got := c.Summary(); !strings.HasSuffix(got, tc.summary) {
+				t.Errorf("Summary =\n%s\nwant sufixo\n%s", got, tc.summary)
+			}
+		})
+	}
+	c.Clear()
+	if c.Shipping != nil {
+		t.Error("Clear manteve o frete")
+	}
+}
+
+// Mudar os itens invalida a cotação (peso e subtotal mudaram).
+func TestCartChangeDropsShipping(t *testing.T) {
+	cases := []struct {
+		name string
+		op   func(c *Cart)
+		keep bool
+	}{
+		{"add", func(c *Cart) { c.Add(Item{ProductID: "2", UnitPrice: 100}) }, false},
+		{"add mesma linha", func(c *Cart) { c.Add(Item{ProductID: "1", UnitPrice: 100}) }, false},
+		{"remove", func(c *Cart) { c.Remove("1", "", 1) }, false},
+		{"remove ausente", func(c *Cart) { c.Remove("9", "", 1) }, true},
+		{"clear", func(c *Cart) { c.Clear() }, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var c Cart
+			c.Add(Item{ProductID: "1", Quantity: 2, UnitPrice: 100})
+			c.Shipping = &Shipping{Name: "PAC", Price: 1000}
+			tc.op(&c)
+			if (c.Shipping != nil) != tc.keep {
+				t.Errorf("Shipping = %+v, keep = %v", c.Shipping, tc.keep)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package cart
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"pac-lead-agent/internal/money"
+)
+
+// OrderDraft é o rascunho de pedido enviado ao PacLead/CRM quando o lead confirma.
+type OrderDraft struct {
+	ID        string       `json:"id"`
+	Status    string       `json:"status"`
+	CNPJ      string       `json:"cnpj_empresa"`
+	OrgID     string       `json:"org_id,omitempty"`
+	Number    string       `json:"numero"`
+	Customer  string       `json:"nome_cliente,omitempty"`
+	Document  string       `json:"documento_cliente,omitempty"`
+	Items     []Item       `json:"itens"`
+	Subtotal  money.Amount `json:"subtotal_centavos"`
+	Shipping  *Shipping    `json:"frete,omitempty"`
+	Total     money.Amount `json:"total_centavos"`
+	CreatedAt time.Time    `json:"criado_em"`
+}
+
+// NewOrderDraft congela o carrinho atual em um rascunho com ID único.
+func NewOrderDraft(c Cart, cnpj, orgID, number, customer string) OrderDraft {
+	items := make([]Item, len(c.Items))
+	copy(items, c.Items)
+	return OrderDraft{
+		ID:        newID(),
+		Status:    "draft",
+		CNPJ:      cnpj,
+		OrgID:     orgID,
+		Number:    number,
+		Customer:  customer,
+		Items:     items,
+		Subtotal:  c.Subtotal(),
+		Shipping:  c.Shipping,
+		Total:     c.Total(),
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return "PED-" + hex.EncodeToString(b[:])
+}
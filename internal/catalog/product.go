@@ -0,0 +1,228 @@
+// Package catalog interpreta os registros de /produtos do PacLead (:8889) em
+// produtos tipados, com preço, estoque e variações (tamanho/cor).
+package catalog
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"pac-lead-agent/internal/money"
+)
+
+// UnknownStock indica que o catálogo não informa estoque (tratado como disponível).
+const UnknownStock = -1
+
+// Product é um item do catálogo.
+type Product struct {
+	ID           string
+	Name         string
+	Description  string
+	Price        money.Amount
+	Original     money.Amount // preço "de" quando há desconto
+	Installments int
+	WeightKg     float64
+	Stock        int
+	Active       bool
+	CompanyID    string
+	Variants     []Variant
+	Raw          map[string]any
+}
+
+// Variant é uma combinação vendável (SKU) do produto.
+type Variant struct {
+	SKU   string       `json:"sku"`
+	Size  string       `json:"size,omitempty"`
+	Color string       `json:"color,omitempty"`
+	Stock int          `json:"stock"`
+	Price money.Amount `json:"price,omitempty"` // 0 = usa o preço do produto
+}
+
+// Label descreve a variação para o lead ("M / Azul").
+func (v Variant) Label() string {
+	var parts []string
+	for _, s := range []string{v.Size, v.Color} {
+		if s != "" {
+			parts = append(parts, s)
+		}
+	}
+	if len(parts) == 0 {
+		return v.SKU
+	}
+	return strings.Join(parts, " / ")
+}
+
+func (v Variant) Available() bool { return v.Stock != 0 }
+
+// SpecSheetURL devolve o link do PDF/ficha técnica do produto, se o catálogo tiver.
+func (p Product) SpecSheetURL() string {
+	return strings.TrimSpace(str(first(p.Raw, "ficha_tecnica", "ficha_tecnica_url", "pdf", "pdf_url", "manual", "documento")))
+}
+
+// Available considera o produto vendável se estiver ativo e houver estoque no
+// produto ou em alguma variação.
+func (p Product) Available() bool {
+	if !p.Active {
+		return false
+	}
+	if len(p.Variants) > 0 {
+		return len(p.AvailableVariants()) > 0
+	}
+	return p.Stock != 0
+}
+
+func (p Product) AvailableVariants() []Variant {
+	out := make([]Variant, 0, len(p.Variants))
+	for _, v := range p.Variants {
+		if v.Available() {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// FindVariant procura por SKU ou pelo rótulo (sem diferenciar maiúsculas).
+func (p Product) FindVariant(q string) (Variant, bool) {
+	q = strings.TrimSpace(q)
+	for _, v := range p.Variants {
+		if strings.EqualFold(v.SKU, q) || strings.EqualFold(v.Label(), q) {
+			return v, true
+		}
+	}
+	// aceita só o tamanho ou só a cor quando isso identifica uma única variação
+	var found []Variant
+	for _, v := range p.Variants {
+		if strings.EqualFold(v.Size, q) || strings.EqualFold(v.Color, q) {
+			found = append(found, v)
+		}
+	}
+	if len(found) == 1 {
+		return found[0], true
+	}
+	return Variant{}, false
+}
+
+// PriceFor devolve o preço da variação (ou do produto).
+func (p Product) PriceFor(v Variant) money.Amount {
+	if v.Price > 0 {
+		return v.Price
+	}
+	return p.Price
+}
+
+// Parse converte um registro do catálogo. Campos ausentes ficam com valores
+// neutros (estoque desconhecido, ativo).
+func Parse(m map[string]any) Product {
+	p := Product{
+		ID:          str(first(m, "id", "id_produto")),
+		Name:        strings.TrimSpace(str(m["nome"])),
+		Description: strings.TrimSpace(str(m["descricao"])),
+		Stock:       stock(first(m, "estoque", "quantidade", "stock", "em_estoque")),
+		Active:      true,
+		CompanyID:   str(first(m, "id_empresa", "empresa_id", "company_id")),
+		Raw:         m,
+	}
+	if v, ok := first(m, "ativo", "active").(bool); ok {
+		p.Active = v
+	}
+	p.Price, p.Original, p.Installments = pricing(m)
+	p.WeightKg = float(first(m, "peso_kg", "peso"))
+	if list, ok := first(m, "variacoes", "variantes", "variants").([]any); ok {
+		for _, it := range list {
+			vm, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			v := Variant{
+				SKU:   str(first(vm, "sku", "id", "codigo")),
+				Size:  strings.TrimSpace(str(first(vm, "tamanho", "size"))),
+				Color: strings.TrimSpace(str(first(vm, "cor", "color"))),
+				Stock: stock(first(vm, "estoque", "quantidade", "stock")),
+			}
+			v.Price, _ = money.Parse(first(vm, "preco", "price"))
+			if v.SKU == "" {
+				v.SKU = p.ID + "-" + v.Label()
+			}
+			p.Variants = append(p.Variants, v)
+		}
+	}
+	return p
+}
+
+// pricing aceita preco em reais (string/float) ou em centavos (preco_centavos),
+// e trata preco_promocional como preço efetivo quando presente.
+func pricing(m map[string]any) (price, original money.Amount, installments int) {
+	price, ok := money.ParseCents(first(m, "preco_centavos", "preco_cents"))
+	if !ok {
+		price, _ = money.Parse(m["preco"])
+	}
+	if promo, ok := money.Parse(first(m, "preco_promocional", "preco_oferta")); ok && promo > 0 && (price <= 0 || promo < price) {
+		original, price = price, promo
+	}
+	if o, ok := money.Parse(first(m, "preco_original", "preco_de")); ok && o > price {
+		original = o
+	}
+	installments = int(float(first(m, "parcelas", "max_parcelas")))
+	return price, original, installments
+}
+
+// first devolve o primeiro valor não nulo entre as chaves.
+func first(m map[string]any, keys ...string) any {
+	for _, k := range keys {
+		if v, ok := m[k]; ok && v != nil {
+			return v
+		}
+	}
+	return nil
+}
+
+func str(v any) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case json.Number:
+		return x.String()
+	}
+	return ""
+}
+
+func float(v any) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case string:
+		f, _ := strconv.ParseFloat(strings.Replace(strings.TrimSpace(x), ",", ".", 1), 64)
+		return f
+	}
+	return 0
+}
+
+func stock(v any) int {
+	switch x := v.(type) {
+	case nil:
+		return UnknownStock
+	case float64:
+		if x < 0 {
+			return 0
+		}
+		return int(x)
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(x))
+		if err != nil {
+			return UnknownStock
+		}
+		if n < 0 {
+			return 0
+		}
+		return n
+	case bool:
+		// alguns catálogos usam "em_estoque": true/false
+		if x {
+			return UnknownStock
+		}
+		return 0
+	}
+	return UnknownStock
+}
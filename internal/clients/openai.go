@@ -110,6 +110,173 @@ func (c *OpenAI) CreateRunWithInstructions(ctx context.Context, threadID, instru
 	return out.ID, nil
 }
 
+// RunOptions ajusta um run: instructions por tenant e tools adicionais (function calling).
+type RunOptions struct {
+	Instructions string
+	// AdditionalInstructions é somado às instructions só neste run (ex.: follow-up).
+	AdditionalInstructions string
+	Tools                  []map[string]any
+}
+
+// CreateRunWithOptions cria um run com instructions e tools. Quando há tools, o
+// Assistants API substitui as do assistente; por isso as ferramentas já
+// configuradas no assistente (ex.: file_search) são mantidas na lista.
+func (c *OpenAI) CreateRunWithOptions(ctx context.Context, threadID string, opts RunOptions) (string, error) {
+	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs", threadID)
+	body := map[string]any{
+		"assistant_id": c.AssistantID,
+	}
+	if strings.TrimSpace(opts.Instructions) != "" {
+		body["instructions"] = opts.Instructions
+	}
+	if strings.TrimSpace(opts.AdditionalInstructions) != "" {
+		body["additional_instructions"] = opts.AdditionalInstructions
+	}
+	if len(opts.Tools) > 0 {
+		// sem as tools do assistente o run perderia file_search/code_interpreter
+		tools, err := c.AssistantTools(ctx)
+		if err != nil {
+			return "", fmt.Errorf("openai assistant tools: %w", err)
+		}
+		body["tools"] = append(tools, opts.Tools...)
+	}
+	req, _ := c.newReq(ctx, "POST", url, body)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		ID    string `json:"id"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", err
+	}
+	if out.ID == "" && out.Error != nil {
+		return "", fmt.Errorf("openai create run: %s", out.Error.Message)
+	}
+	return out.ID, nil
+}
+
+// AssistantTools devolve as tools configuradas no assistente (exceto functions,
+// que são sempre fornecidas pelo backend).
+func (c *OpenAI) AssistantTools(ctx context.Context) ([]map[string]any, error) {
+	url := "https://api.openai.com/v1/assistants/" + c.AssistantID
+	req, _ := c.newReq(ctx, "GET", url, nil)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, fmt.Errorf("assistants http %d", resp.StatusCode)
+	}
+	var out struct {
+		Tools []map[string]any `json:"tools"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	tools := make([]map[string]any, 0, len(out.Tools))
+	for _, t := range out.Tools {
+		if t["type"] != "function" {
+			tools = append(tools, t)
+		}
+	}
+	return tools, nil
+}
+
+// ToolCall é uma chamada de function pedida pelo assistente (status requires_action).
+type ToolCall struct {
+	ID        string
+	Name      string
+	Arguments string
+}
+
+// Run é o estado resumido de um run.
+type Run struct {
+	ID        string
+	Status    string
+	ToolCalls []ToolCall
+}
+
+func (c *OpenAI) GetRun(ctx context.Context, threadID, runID string) (Run, error) {
+	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs/%s", threadID, runID)
+	req, _ := c.newReq(ctx, "GET", url, nil)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return Run{}, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		ID             string `json:"id"`
+		Status         string `json:"status"`
+		RequiredAction *struct {
+			SubmitToolOutputs struct {
+				ToolCalls []struct {
+					ID       string `json:"id"`
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"submit_tool_outputs"`
+		} `json:"required_action"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return Run{}, err
+	}
+	run := Run{ID: out.ID, Status: out.Status}
+	if out.RequiredAction != nil {
+		for _, tc := range out.RequiredAction.SubmitToolOutputs.ToolCalls {
+			run.ToolCalls = append(run.ToolCalls, ToolCall{ID: tc.ID, Name: tc.Function.Name, Arguments: tc.Function.Arguments})
+		}
+	}
+	return run, nil
+}
+
+// ToolOutput é a resposta de uma ToolCall.
+type ToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
+func (c *OpenAI) SubmitToolOutputs(ctx context.Context, threadID, runID string, outputs []ToolOutput) error {
+	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs/%s/submit_tool_outputs", threadID, runID)
+	req, _ := c.newReq(ctx, "POST", url, map[string]any{"tool_outputs": outputs})
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("openai submit_tool_outputs http %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// CancelRun cancela um run em andamento (timeout), liberando a thread para
+// novas mensagens. Runs já encerrados respondem 400 e são ignorados.
+func (c *OpenAI) CancelRun(ctx context.Context, threadID, runID string) error {
+	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/runs/%s/cancel", threadID, runID)
+	req, _ := c.newReq(ctx, "POST", url, map[string]any{})
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("openai cancel run http %d", resp.StatusCode)
+	}
+	return nil
+}
+
 func (c *OpenAI) LastMessageText(ctx context.Context, threadID string) (string, error) {
 	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/messages?order=desc&limit=1", threadID)
 	req, _ := c.newReq(ctx, "GET", url, nil)
@@ -137,28 +304,239 @@ func (c *OpenAI) LastMessageText(ctx context.Context, threadID string) (string,
 	return "", nil
 }
 
+// ThreadMessage é uma mensagem de thread reduzida a papel e texto.
+type ThreadMessage struct {
+	Role string
+	Text string
+	// CreatedAt é o timestamp Unix da mensagem (preenchido por ThreadMessages).
+	CreatedAt int64 `json:",omitempty"`
+}
+
+// RecentMessages devolve as últimas limit mensagens da thread em ordem cronológica.
+func (c *OpenAI) RecentMessages(ctx context.Context, threadID string, limit int) ([]ThreadMessage, error) {
+	url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/messages?order=desc&limit=%d", threadID, limit)
+	req, _ := c.newReq(ctx, "GET", url, nil)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Data []struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Type string `json:"type"`
+				Text struct {
+					Value string `json:"value"`
+				} `json:"text"`
+			} `json:"content"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	msgs := make([]ThreadMessage, 0, len(out.Data))
+	for i := len(out.Data) - 1; i >= 0; i-- {
+		d := out.Data[i]
+		var parts []string
+		for _, c := range d.Content {
+			if c.Type == "text" && c.Text.Value != "" {
+				parts = append(parts, c.Text.Value)
+			}
+		}
+		if len(parts) > 0 {
+			msgs = append(msgs, ThreadMessage{Role: d.Role, Text: strings.Join(parts, "\n")})
+		}
+	}
+	return msgs, nil
+}
+
+// ThreadMessages devolve todas as mensagens da thread em ordem cronológica,
+// paginando de 100 em 100 (exportação LGPD).
+func (c *OpenAI) ThreadMessages(ctx context.Context, threadID string) ([]ThreadMessage, error) {
+	var msgs []ThreadMessage
+	after := ""
+	for {
+		url := fmt.Sprintf("https://api.openai.com/v1/threads/%s/messages?order=asc&limit=100", threadID)
+		if after != "" {
+			url += "&after=" + after
+		}
+		req, _ := c.newReq(ctx, "GET", url, nil)
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var out struct {
+			Data []struct {
+				Role      string `json:"role"`
+				CreatedAt int64  `json:"created_at"`
+				Content   []struct {
+					Type string `json:"type"`
+					Text struct {
+						Value string `json:"value"`
+					} `json:"text"`
+				} `json:"content"`
+			} `json:"data"`
+			LastID  string `json:"last_id"`
+			HasMore bool   `json:"has_more"`
+		}
+		if resp.StatusCode >= 400 {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("openai list messages http %d", resp.StatusCode)
+		}
+		err = json.NewDecoder(resp.Body).Decode(&out)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range out.Data {
+			var parts []string
+			for _, ct := range d.Content {
+				if ct.Type == "text" && ct.Text.Value != "" {
+					parts = append(parts, ct.Text.Value)
+				}
+			}
+			msgs = append(msgs, ThreadMessage{Role: d.Role, Text: strings.Join(parts, "\n"), CreatedAt: d.CreatedAt})
+		}
+		if !out.HasMore || out.LastID == "" || out.LastID == after {
+			return msgs, nil
+		}
+		after = out.LastID
+	}
+}
+
+// DeleteThread apaga a thread e suas mensagens na OpenAI (pedidos de exclusão/LGPD).
+func (c *OpenAI) DeleteThread(ctx context.Context, threadID string) error {
+	req, _ := c.newReq(ctx, "DELETE", fmt.Sprintf("https://api.openai.com/v1/threads/%s", threadID), nil)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("openai delete thread: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ChatJSON chama /chat/completions com structured outputs (json_schema estrito)
+// e devolve o JSON produzido pelo modelo.
+func (c *OpenAI) ChatJSON(ctx context.Context, model, system, user, schemaName string, schema map[string]any) ([]byte, error) {
+	body := map[string]any{
+		"model": model,
+		"messages": []map[string]any{
+			{"role": "system", "content": system},
+			{"role": "user", "content": user},
+		},
+		"response_format": map[string]any{
+			"type": "json_schema",
+			"json_schema": map[string]any{
+				"name":   schemaName,
+				"strict": true,
+				"schema": schema,
+			},
+		},
+		"temperature": 0,
+	}
+	req, _ := c.newReq(ctx, "POST", "https://api.openai.com/v1/chat/completions", body)
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	var out struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+				Refusal string `json:"refusal"`
+			} `json:"message"`
+		} `json:"choices"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	if out.Error != nil {
+		return nil, fmt.Errorf("openai chat: %s", out.Error.Message)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return nil, fmt.Errorf("openai chat: empty response")
+	}
+	return []byte(out.Choices[0].Message.Content), nil
+}
+
+// SpeechOptions define modelo, voz, estilo (instructions) e formato do áudio.
+type SpeechOptions struct {
+	Model        string
+	Voice        string
+	Instructions string
+	// Format é o response_format: "opus" (nota de voz do WhatsApp), "mp3", ...
+	Format string
+}
+
+// DefaultSpeech é a configuração usada quando o tenant não define a sua.
+var DefaultSpeech = SpeechOptions{
+	Model:        "gpt-4o-mini-tts",
+	Voice:        "ballad",
+	Instructions: "always speak in an animated and inspiring way, ALWAYS in Brazilian Portuguese",
+	Format:       "mp3",
+}
+
 func (c *OpenAI) TextToSpeech(ctx context.Context, text string) (string, error) {
 	// Returns base64 string (mp3)
+	data, err := c.Speech(ctx, text, DefaultSpeech)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Speech gera o áudio do texto e devolve os bytes no formato pedido. Campos
+// vazios em opts caem em DefaultSpeech.
+func (c *OpenAI) Speech(ctx context.Context, text string, opts SpeechOptions) ([]byte, error) {
+	if opts.Model == "" {
+		opts.Model = DefaultSpeech.Model
+	}
+	if opts.Voice == "" {
+		opts.Voice = DefaultSpeech.Voice
+	}
+	if opts.Format == "" {
+		opts.Format = DefaultSpeech.Format
+	}
 	body := map[string]any{
-		"model":        "gpt-4o-mini-tts",
-		"input":        text,
-		"voice":        "ballad",
-		"format":       "mp3",
-		"instructions": "always speak in an animated and inspiring way, ALWAYS in Brazilian Portuguese",
+		"model":           opts.Model,
+		"input":           text,
+		"voice":           opts.Voice,
+		"response_format": opts.Format,
+	}
+	if opts.Instructions != "" {
+		body["instructions"] = opts.Instructions
 	}
 	req, _ := c.newReq(ctx, "POST", "https://api.openai.com/v1/audio/speech", body)
 	resp, err := c.http.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 	data, _ := io.ReadAll(resp.Body)
-	// Some gateways return raw mp3; for consistency we base64-encode if not already JSON.
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, fmt.Errorf("openai speech: status %d", resp.StatusCode)
+	}
+	// Some gateways return JSON with base64 data instead of raw audio.
 	if len(data) > 0 && data[0] == '{' {
 		var tmp struct{ Data string `json:"data"` }
 		if json.Unmarshal(data, &tmp) == nil && tmp.Data != "" {
-			return tmp.Data, nil
+			return base64.StdEncoding.DecodeString(tmp.Data)
 		}
 	}
-	return base64.StdEncoding.EncodeToString(data), nil
+	if len(data) == 0 {
+		return nil, fmt.Errorf("openai speech: empty audio")
+	}
+	return data, nil
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"path"
 	"strings"
 
 	"pac-lead-agent/internal/types"
@@ -44,6 +45,11 @@ func (p *PacLead) postJSON(ctx context.Context, url string, in any, out any) err
 		return err
 	}
 	defer resp.Body.Close()
+	// 4xx/5xx com corpo JSON não é sucesso (ex.: pedido recusado pelo CRM)
+	if resp.StatusCode >= 400 {
+		io.Copy(io.Discard, resp.Body)
+		return &HTTPError{URL: url, Status: resp.StatusCode}
+	}
 	if out != nil {
 		return json.NewDecoder(resp.Body).Decode(out)
 	}
@@ -69,6 +75,26 @@ func (p *PacLead) LeadPost(ctx context.Context, lead types.LeadRecord) (map[stri
 	return out, err
 }
 
+// AnonymizeLead apaga nome, thread e status do lead via /leadpost: o :8889 não
+// tem endpoint de exclusão, então o registro continua existindo com número e
+// CNPJ. Confere lendo o lead de volta e devolve erro se os dados não sumiram.
+func (p *PacLead) AnonymizeLead(ctx context.Context, lead types.LeadRecord) error {
+	anon := types.LeadRecord{ID: lead.ID, Numero: lead.Numero, CNPJCPF: lead.CNPJCPF}
+	if _, err := p.LeadPost(ctx, anon); err != nil && err != io.EOF {
+		return err
+	}
+	out, err := p.LeadsGeral(ctx, lead.Numero, lead.CNPJCPF)
+	if err != nil {
+		return fmt.Errorf("conferir anonimização: %w", err)
+	}
+	for _, k := range []string{"nome", "Thread_id", "thread_id"} {
+		if v, _ := out[k].(string); strings.TrimSpace(v) != "" {
+			return fmt.Errorf("lead ainda tem %s após anonimização", k)
+		}
+	}
+	return nil
+}
+
 func (p *PacLead) Produtos(ctx context.Context, cnpj string, id *string) ([]map[string]any, error) {
 	url := fmt.Sprintf("%s/produtos?cnpj=%s", p.Base, cnpj)
 	if id != nil && *id != "" {
@@ -87,15 +113,139 @@ func (p *PacLead) Produtos(ctx context.Context, cnpj string, id *string) ([]map[
 	return out, nil
 }
 
+// ProdutoImagemURL monta a URL interna (:8889) da imagem de um produto.
+func (p *PacLead) ProdutoImagemURL(id, companyID string) string {
+	q := url.Values{}
+	q.Set("id", id)
+	q.Set("id_empresa", companyID)
+	return p.Base + "/produtos/imagem?" + q.Encode()
+}
+
+// ProdutoImagem baixa a imagem de um produto; retorna bytes e Content-Type informado.
+func (p *PacLead) ProdutoImagem(ctx context.Context, id, companyID string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", p.ProdutoImagemURL(id, companyID), nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		io.Copy(io.Discard, resp.Body)
+		return nil, "", fmt.Errorf("produtos/imagem http %d", resp.StatusCode)
+	}
+	// Limita a leitura para não carregar arquivos arbitrariamente grandes
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 20<<20))
+	if err != nil {
+		return nil, "", err
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
 // UpdateCRMLead mirrors the :8082 /leads update payload used in the workflow.
 // Provide exactly the fields your CRM expects.
 func (p *PacLead) UpdateCRMLead(ctx context.Context, payload any) error {
+	return p.SendCRMEvent(ctx, "", "", payload)
+}
+
+// HTTPError é uma resposta de erro do PacLead; 4xx (exceto 429) não é temporário.
+type HTTPError struct {
+	URL    string
+	Status int
+}
+
+func (e *HTTPError) Error() string { return fmt.Sprintf("%s: http %d", e.URL, e.Status) }
+
+func (e *HTTPError) Temporary() bool {
+	return e.Status >= 500 || e.Status == http.StatusTooManyRequests
+}
+
+// EndpointError é um endpoint de evento fora da base do CRM; não adianta
+// tentar de novo.
+type EndpointError struct {
+	Endpoint string
+}
+
+func (e *EndpointError) Error() string {
+	return fmt.Sprintf("crm endpoint %q: use um caminho relativo à base do CRM", e.Endpoint)
+}
+
+func (e *EndpointError) Temporary() bool { return false }
+
+// crmURL resolve o endpoint sob a base do CRM. Só caminhos são aceitos: o
+// endpoint vem das settings do tenant e não pode levar o envio a outro host.
+func (p *PacLead) crmURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.User != nil || u.Opaque != "" {
+		return "", &EndpointError{Endpoint: endpoint}
+	}
+	// path.Join limpa "..": o caminho não sai da base
+	out := p.CRM + path.Join("/", u.Path)
+	if u.RawQuery != "" {
+		out += "?" + u.RawQuery
+	}
+	return out, nil
+}
+
+// SendCRMEvent envia um evento ao CRM com header Idempotency-Key. endpoint é um
+// caminho sob a base do CRM (ex.: "/deals"); vazio usa {CRM}/leads (mesmo
+// destino de UpdateCRMLead).
+func (p *PacLead) SendCRMEvent(ctx context.Context, endpoint, idempotencyKey string, payload any) error {
 	// Se CRM não configurado, não faz nada
 	if p.CRM == "" {
 		return nil
 	}
-	url := p.CRM + "/leads"
-	return p.postJSON(ctx, url, payload, nil)
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		endpoint = "/leads"
+	}
+	endpoint, err := p.crmURL(endpoint)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(payload); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, &buf)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 400 {
+		return &HTTPError{URL: endpoint, Status: resp.StatusCode}
+	}
+	return nil
+}
+
+// PostOrderDraft envia um rascunho de pedido. endpoint vazio usa {CRM}/pedidos.
+// Status >= 400 volta como *HTTPError (o pedido não foi criado).
+func (p *PacLead) PostOrderDraft(ctx context.Context, endpoint string, draft any) (map[string]any, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		if p.CRM == "" {
+			return nil, fmt.Errorf("order endpoint not configured")
+		}
+		endpoint = p.CRM + "/pedidos"
+	}
+	var out map[string]any
+	err := p.postJSON(ctx, endpoint, draft, &out)
+	if err == io.EOF {
+		// resposta sem corpo
+		err = nil
+	}
+	return out, err
 }
 
 // GetAgentSettings consulta a plataforma por configurações do agente (prompt custom por tenant).
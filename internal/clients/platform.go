@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -64,3 +65,41 @@ func (p *Platform) GetAgentSettings(ctx context.Context, orgID, flowID string) (
 	}
 	return out, nil
 }
+
+// GetFlow busca a definição do fluxo (/api/agent/flows/<flowID>) na Plataforma.
+// Devolve nil se o fluxo não existir.
+func (p *Platform) GetFlow(ctx context.Context, orgID, flowID string) (json.RawMessage, error) {
+	if p == nil || p.Base == "" {
+		return nil, fmt.Errorf("platform base url not configured")
+	}
+	if strings.TrimSpace(flowID) == "" {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Base+"/api/agent/flows/"+url.PathEscape(flowID), nil)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(orgID) != "" {
+		req.Header.Set("X-Org-ID", orgID)
+	}
+	req.Header.Set("X-Flow-ID", flowID)
+	req.Header.Set("Accept", "application/json")
+
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if res.StatusCode >= 400 {
+		return nil, fmt.Errorf("platform flow http %d", res.StatusCode)
+	}
+	var out json.RawMessage
+	if err := json.NewDecoder(res.Body).Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
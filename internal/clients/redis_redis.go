@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"pac-lead-agent/internal/phone"
 )
 
 type Redis struct {
@@ -29,7 +31,9 @@ func NewRedisFromEnv() *Redis {
 }
 
 func (c *Redis) healthy() bool { return c != nil && c.rdb != nil }
-func bufferKey(number string) string { return "NUMBER_buffer_helsenia:" + strings.TrimSpace(number) }
+// bufferKey usa a chave canônica do número: o mesmo cliente com e sem nono
+// dígito (ou via JID) cai no mesmo buffer.
+func bufferKey(number string) string { return "NUMBER_buffer_helsenia:" + phone.ParseJID(number).Key() }
 
 func (c *Redis) PushBuffer(ctx context.Context, number, message string, ttl time.Duration) error {
 	if !c.healthy() {
@@ -3,15 +3,39 @@ package clients
 import (
     "bytes"
     "context"
+    "encoding/base64"
     "encoding/json"
     "fmt"
+    "io"
     "net/http"
+    "strings"
 )
 
 type Whats struct {
     Base  string
     Token string
     http  *http.Client
+    // OnSent é chamado após cada envio bem-sucedido com o path, o corpo enviado
+    // e o id da mensagem devolvido pelo gateway (acompanhamento do lead, transcrição).
+    OnSent func(ctx context.Context, path string, body map[string]any, messageID string)
+    // OnFailed é chamado quando um envio falha (transcrição com status "failed").
+    OnFailed func(ctx context.Context, path string, body map[string]any, err error)
+}
+
+// carouselLimits é o máximo de cards aceito por carrossel em cada gateway.
+var carouselLimits = map[string]int{
+    "uazapi":    5,
+    "zapster":   5,
+    "evolution": 10,
+    "cloud":     10,
+}
+
+// CarouselLimit devolve o máximo de cards por carrossel do provedor (padrão 5).
+func CarouselLimit(provider string) int {
+    if n, ok := carouselLimits[strings.ToLower(strings.TrimSpace(provider))]; ok {
+        return n
+    }
+    return 5
 }
 
 func NewWhats(base, token string) *Whats {
@@ -33,17 +57,47 @@ func (w *Whats) do(ctx context.Context, path string, body any) error {
     req.Header.Set("token", w.Token)
     req.Header.Set("Accept", "application/json")
     req.Header.Set("Content-Type", "application/json")
+    // só envios de mensagem contam para o acompanhamento (presença/leitura não)
+    m, track := body.(map[string]any)
+    track = track && strings.HasPrefix(path, "/send/")
     resp, err := w.http.Do(req)
+    if err == nil && resp.StatusCode >= http.StatusMultipleChoices {
+        resp.Body.Close()
+        err = fmt.Errorf("whats api %s: status %d", path, resp.StatusCode)
+    }
     if err != nil {
+        if track && w.OnFailed != nil {
+            w.OnFailed(ctx, path, m, err)
+        }
         return err
     }
     defer resp.Body.Close()
-    if resp.StatusCode >= http.StatusMultipleChoices {
-        return fmt.Errorf("whats api %s: status %d", path, resp.StatusCode)
+    if track && w.OnSent != nil {
+        w.OnSent(ctx, path, m, sentMessageID(resp.Body))
     }
     return nil
 }
 
+// sentMessageID lê o id da mensagem enviada na resposta do gateway (vazio se
+// não houver), usado para casar os recibos de entrega.
+func sentMessageID(r io.Reader) string {
+    var out map[string]any
+    if err := json.NewDecoder(io.LimitReader(r, 1<<20)).Decode(&out); err != nil {
+        return ""
+    }
+    for _, k := range []string{"messageid", "messageId", "id"} {
+        if s, ok := out[k].(string); ok && s != "" {
+            return s
+        }
+    }
+    if key, ok := out["key"].(map[string]any); ok {
+        if s, ok := key["id"].(string); ok {
+            return s
+        }
+    }
+    return ""
+}
+
 func (w *Whats) SendText(ctx context.Context, number, text string) error {
     return w.do(ctx, "/send/text", map[string]any{
         "number": number,
@@ -61,12 +115,158 @@ func (w *Whats) SendCarousel(ctx context.Context, number, text string, cards []m
     })
 }
 
+// SendAudioBase64 envia o áudio como nota de voz (push-to-talk). Para aparecer
+// como gravação no WhatsApp o áudio deve ser OGG/Opus.
 func (w *Whats) SendAudioBase64(ctx context.Context, number, b64 string) error {
-    // Example for Zapster-like endpoint: adapt as needed.
-    // If your Whats gateway accepts audio via the same base URL, adjust path here.
-    return w.do(ctx, "/send/audio", map[string]any{
+    return w.do(ctx, "/send/media", map[string]any{
+        "number": number,
+        "file":   b64,
+        "type":   "ptt",
+    })
+}
+
+// SendButtons envia uma mensagem com botões de resposta rápida.
+// Cada choice segue o formato do gateway: "Texto|id".
+func (w *Whats) SendButtons(ctx context.Context, number, text string, choices []string) error {
+    return w.do(ctx, "/send/menu", map[string]any{
+        "number":  number,
+        "type":    "button",
+        "text":    text,
+        "choices": choices,
+    })
+}
+
+// SendImageBase64 envia uma imagem (PNG/JPEG em base64) com legenda opcional.
+func (w *Whats) SendImageBase64(ctx context.Context, number, b64, caption string) error {
+    return w.do(ctx, "/send/media", map[string]any{
         "number": number,
+        "type":   "image",
         "file":   b64,
-        "type":   "audio",
+        "text":   caption,
+    })
+}
+
+// SendList envia uma mensagem de lista (menu com botão). Choices seguem o
+// formato do gateway: "[Seção]" abre uma seção e "Texto|id|descrição" é uma linha.
+func (w *Whats) SendList(ctx context.Context, number, text, buttonText string, choices []string) error {
+    return w.do(ctx, "/send/menu", map[string]any{
+        "number":     number,
+        "type":       "list",
+        "text":       text,
+        "listButton": buttonText,
+        "choices":    choices,
+    })
+}
+
+// SendPresence mostra o status do contato no chat ("composing", "recording",
+// "paused"). O gateway mantém a presença por delayMs milissegundos.
+func (w *Whats) SendPresence(ctx context.Context, number, presence string, delayMs int) error {
+    return w.do(ctx, "/message/presence", map[string]any{
+        "number":   number,
+        "presence": presence,
+        "delay":    delayMs,
+    })
+}
+
+// MarkRead marca mensagens recebidas como lidas (confirmação azul).
+func (w *Whats) MarkRead(ctx context.Context, ids ...string) error {
+    if len(ids) == 0 {
+        return nil
+    }
+    return w.do(ctx, "/message/markread", map[string]any{
+        "id": ids,
+    })
+}
+
+// Media é um arquivo a enviar: por URL pública ou pelos bytes (enviados em base64).
+type Media struct {
+    URL      string
+    Data     []byte
+    MimeType string
+    FileName string // nome exibido em documentos
+}
+
+func (m Media) file() string {
+    if m.URL != "" {
+        return m.URL
+    }
+    return base64.StdEncoding.EncodeToString(m.Data)
+}
+
+// SendMedia envia mídia do tipo informado ("image", "video", "document",
+// "audio", "ptt", "sticker") com legenda opcional.
+func (w *Whats) SendMedia(ctx context.Context, number, kind string, m Media, caption string) error {
+    if m.URL == "" && len(m.Data) == 0 {
+        return fmt.Errorf("whats api: mídia vazia")
+    }
+    body := map[string]any{
+        "number": number,
+        "type":   kind,
+        "file":   m.file(),
+    }
+    if caption != "" {
+        body["text"] = caption
+    }
+    if m.FileName != "" {
+        body["docName"] = m.FileName
+    }
+    if m.MimeType != "" {
+        body["mimetype"] = m.MimeType
+    }
+    return w.do(ctx, "/send/media", body)
+}
+
+// SendImage envia uma imagem (URL ou bytes) com legenda.
+func (w *Whats) SendImage(ctx context.Context, number string, m Media, caption string) error {
+    return w.SendMedia(ctx, number, "image", m, caption)
+}
+
+// SendDocument envia um arquivo (PDF de catálogo, orçamento, ficha técnica).
+func (w *Whats) SendDocument(ctx context.Context, number string, m Media, caption string) error {
+    return w.SendMedia(ctx, number, "document", m, caption)
+}
+
+// SendVideo envia um vídeo com legenda.
+func (w *Whats) SendVideo(ctx context.Context, number string, m Media, caption string) error {
+    return w.SendMedia(ctx, number, "video", m, caption)
+}
+
+// Location é um pin de localização.
+type Location struct {
+    Latitude  float64
+    Longitude float64
+    Name      string
+    Address   string
+}
+
+// SendLocation envia um pin de localização (ex.: endereço da loja).
+func (w *Whats) SendLocation(ctx context.Context, number string, loc Location) error {
+    return w.do(ctx, "/send/location", map[string]any{
+        "number":    number,
+        "latitude":  loc.Latitude,
+        "longitude": loc.Longitude,
+        "name":      loc.Name,
+        "address":   loc.Address,
+    })
+}
+
+// Contact é um cartão de contato (vCard).
+type Contact struct {
+    FullName     string
+    Phone        string
+    Organization string
+    Email        string
+    URL          string
+}
+
+// SendContact envia um cartão de contato (ex.: vendedor responsável).
+func (w *Whats) SendContact(ctx context.Context, number string, c Contact) error {
+    return w.do(ctx, "/send/contact", map[string]any{
+        "number":       number,
+        "fullName":     c.FullName,
+        "phoneNumber":  c.Phone,
+        "organization": c.Organization,
+        "email":        c.Email,
+        "url":          c.URL,
     })
 }
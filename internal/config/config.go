@@ -1,6 +1,10 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 type Config struct {
 	Addr               string
@@ -13,6 +17,14 @@ type Config struct {
 	PlatformBaseURL    string // <— NOVO: backend da plataforma (para /api/agent/settings)
 	DefaultPrompt      string // <— NOVO: fallback de prompt padrão
 	RedisURL           string
+	PublicBaseURL      string // URL pública (HTTPS) deste serviço, usada no proxy de mídia
+	PacLeadCompanyID   string // id_empresa padrão quando o tenant não informa
+	WhatsProvider      string // gateway de WhatsApp (uazapi, evolution, zapster, ...)
+	CarouselLimit      int    // cards por carrossel; 0 = limite padrão do provedor
+	OrderDraftURL      string // endpoint de rascunhos de pedido; vazio = {CRM}/pedidos
+	ExtractionModel    string // modelo usado na extração/qualificação do lead
+	APIToken           string // token Bearer das APIs administrativas (/api/...)
+	InstanceTokens     map[string]string // tokens por instância (id=token,...) para jobs fora do webhook
 }
 
 func Load() Config {
@@ -27,12 +39,41 @@ func Load() Config {
 		PlatformBaseURL:   getenv("PLATFORM_BASE_URL", ""),     // e.g. https://plataforma-pac-lead-backend-production.up.railway.app
 		DefaultPrompt:     getenv("DEFAULT_PROMPT", ""),        // se vazio, usamos o default embarcado
 		RedisURL:          os.Getenv("REDIS_URL"),
+		PublicBaseURL:     getenv("PUBLIC_BASE_URL", ""),   // e.g. https://agente.paclead.com.br
+		PacLeadCompanyID:  getenv("PACLEAD_COMPANY_ID", "1"),
+		WhatsProvider:     getenv("WHATS_PROVIDER", "uazapi"),
+		CarouselLimit:     getenvInt("WHATS_CAROUSEL_LIMIT", 0),
+		OrderDraftURL:     getenv("ORDER_DRAFT_URL", ""),
+		ExtractionModel:   getenv("OPENAI_EXTRACTION_MODEL", "gpt-4o-mini"),
+		APIToken:          os.Getenv("AGENT_API_TOKEN"),
+		InstanceTokens:    parsePairs(os.Getenv("UAZAPI_INSTANCE_TOKENS")),
 	}
 }
 
+// parsePairs lê "id=token,id2=token2" (espaços ignorados).
+func parsePairs(s string) map[string]string {
+	out := map[string]string{}
+	for _, kv := range strings.Split(s, ",") {
+		k, v, ok := strings.Cut(kv, "=")
+		if k, v = strings.TrimSpace(k), strings.TrimSpace(v); ok && k != "" && v != "" {
+			out[k] = v
+		}
+	}
+	return out
+}
+
 func getenv(k, def string) string {
 	if v := os.Getenv(k); v != "" {
 		return v
 	}
 	return def
 }
+
+func getenvInt(k string, def int) int {
+	if v := os.Getenv(k); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			return n
+		}
+	}
+	return def
+}
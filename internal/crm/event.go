@@ -0,0 +1,44 @@
+// Package crm sincroniza eventos da conversa com o CRM do PacLead (:8082),
+// com mapeamento de campos por tenant, chaves de idempotência e retentativas.
+package crm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+)
+
+// EventType é o marco da conversa enviado ao CRM.
+type EventType string
+
+const (
+	EventNewLead       EventType = "new_lead"
+	EventQualified     EventType = "qualified"
+	EventProductsShown EventType = "products_shown"
+	EventCartCreated   EventType = "cart_created"
+	EventHandoff       EventType = "handoff"
+	EventOrderPlaced   EventType = "order_placed"
+	// EventProfileUpdated leva os dados extraídos da conversa (BANT, cadastro, score).
+	EventProfileUpdated EventType = "profile_updated"
+)
+
+// Event é um marco da conversa de um lead.
+type Event struct {
+	Type   EventType
+	CNPJ   string
+	OrgID  string
+	Number string
+	Name   string
+	// Ref diferencia ocorrências do mesmo tipo (ex.: ID do pedido); vazio
+	// significa "uma vez por lead".
+	Ref  string
+	Data map[string]any
+	At   time.Time
+}
+
+// IdempotencyKey é determinística: reenvios do mesmo evento geram a mesma chave.
+func (e Event) IdempotencyKey() string {
+	h := sha256.Sum256([]byte(strings.Join([]string{e.CNPJ, e.Number, string(e.Type), e.Ref}, "|")))
+	return hex.EncodeToString(h[:16])
+}
@@ -0,0 +1,104 @@
+package crm
+
+import (
+	"strings"
+	"time"
+)
+
+// Mapping descreve como cada tenant quer receber os eventos (settings "crm_mapping").
+//
+//	{
+//	  "events": ["new_lead", "order_placed"],
+//	  "fields": {"telefone": "number", "nome": "name", "valor": "data.total"},
+//	  "stages": {"qualified": "Qualificado", "order_placed": "Ganho"},
+//	  "stage_field": "etapa",
+//	  "static": {"origem": "whatsapp"}
+//	}
+//
+// Sem "fields", o payload padrão é usado.
+type Mapping struct {
+	Events     []EventType       `json:"events"`
+	Fields     map[string]string `json:"fields"`
+	Stages     map[EventType]any `json:"stages"`
+	StageField string            `json:"stage_field"`
+	Static     map[string]any    `json:"static"`
+	// Endpoint é um caminho sob a base do CRM (PACLEAD_CRM_BASE_URL), ex.:
+	// "/deals"; URLs absolutas são recusadas no envio.
+	Endpoint string `json:"endpoint"`
+}
+
+// Enabled informa se o tenant quer o evento (lista vazia = todos).
+func (m Mapping) Enabled(t EventType) bool {
+	if len(m.Events) == 0 {
+		return true
+	}
+	for _, e := range m.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// Payload monta o corpo enviado ao CRM.
+func (m Mapping) Payload(ev Event) map[string]any {
+	src := source(ev)
+	out := map[string]any{}
+	if len(m.Fields) == 0 {
+		for _, k := range []string{"event", "number", "name", "cnpj", "org_id", "occurred_at"} {
+			out[k] = src[k]
+		}
+		out["data"] = ev.Data
+		out["idempotency_key"] = ev.IdempotencyKey()
+	} else {
+		for field, path := range m.Fields {
+			if v, ok := lookup(src, path); ok {
+				out[field] = v
+			}
+		}
+	}
+	for k, v := range m.Static {
+		out[k] = v
+	}
+	if stage, ok := m.Stages[ev.Type]; ok {
+		f := m.StageField
+		if f == "" {
+			f = "stage"
+		}
+		out[f] = stage
+	}
+	return out
+}
+
+// source expõe os campos do evento para o mapeamento ("data.x" navega em Data).
+func source(ev Event) map[string]any {
+	at := ev.At
+	if at.IsZero() {
+		at = time.Now()
+	}
+	return map[string]any{
+		"event":           string(ev.Type),
+		"number":          ev.Number,
+		"name":            ev.Name,
+		"cnpj":            ev.CNPJ,
+		"org_id":          ev.OrgID,
+		"ref":             ev.Ref,
+		"occurred_at":     at.UTC().Format(time.RFC3339),
+		"idempotency_key": ev.IdempotencyKey(),
+		"data":            ev.Data,
+	}
+}
+
+func lookup(m map[string]any, path string) (any, bool) {
+	var cur any = m
+	for _, part := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[part]; !ok {
+			return nil, false
+		}
+	}
+	return cur, cur != nil
+}
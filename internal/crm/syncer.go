@@ -0,0 +1,107 @@
+package crm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Sender entrega o payload ao CRM (implementado por *clients.PacLead).
+type Sender interface {
+	SendCRMEvent(ctx context.Context, endpoint, idempotencyKey string, payload any) error
+}
+
+// permanent informa se não adianta repetir (erros que expõem Temporary() == false,
+// como respostas HTTP 4xx).
+func permanent(err error) bool {
+	var t interface{ Temporary() bool }
+	return errors.As(err, &t) && !t.Temporary()
+}
+
+type job struct {
+	ev      Event
+	mapping Mapping
+	sender  Sender
+}
+
+// Syncer envia eventos em background, com retentativas e deduplicação.
+type Syncer struct {
+	store   state.Store
+	queue   chan job
+	backoff []time.Duration
+}
+
+const sentTTL = 30 * 24 * time.Hour
+
+func NewSyncer(store state.Store, workers int) *Syncer {
+	s := &Syncer{
+		store:   store,
+		queue:   make(chan job, 256),
+		backoff: []time.Duration{0, 2 * time.Second, 10 * time.Second, time.Minute},
+	}
+	for i := 0; i < workers; i++ {
+		go s.worker()
+	}
+	return s
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultSyncer *Syncer
+)
+
+// Default devolve o Syncer do processo.
+func Default() *Syncer {
+	defaultOnce.Do(func() { defaultSyncer = NewSyncer(state.Default(), 2) })
+	return defaultSyncer
+}
+
+// Emit enfileira o evento se o tenant o habilitou; nunca bloqueia a conversa.
+func (s *Syncer) Emit(sender Sender, m Mapping, ev Event) {
+	if sender == nil || !m.Enabled(ev.Type) {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	select {
+	case s.queue <- job{ev: ev, mapping: m, sender: sender}:
+	default:
+		log.Println("crm: queue full, dropping", ev.Type, ev.Number)
+	}
+}
+
+func (s *Syncer) worker() {
+	for j := range s.queue {
+		s.deliver(j)
+	}
+}
+
+func (s *Syncer) deliver(j job) {
+	key := j.ev.IdempotencyKey()
+	sentKey := "crm:sent:" + key
+	// reserva a chave: evita reenvio entre réplicas e webhooks duplicados
+	if ok, err := s.store.SetNX(context.Background(), sentKey, j.ev.At, sentTTL); err == nil && !ok {
+		return
+	}
+	payload := j.mapping.Payload(j.ev)
+	var err error
+	for _, wait := range s.backoff {
+		time.Sleep(wait)
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		err = j.sender.SendCRMEvent(ctx, j.mapping.Endpoint, key, payload)
+		cancel()
+		if err == nil || permanent(err) {
+			break
+		}
+	}
+	if err != nil {
+		log.Println("crm sync:", err, "event:", j.ev.Type, "numero:", j.ev.Number)
+		// libera a chave para uma próxima ocorrência tentar novamente
+		_ = s.store.Delete(context.Background(), sentKey)
+	}
+}
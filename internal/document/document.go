@@ -0,0 +1,149 @@
+// Package document valida, normaliza e formata CPF e CNPJ, incluindo o CNPJ
+// alfanumérico (IN RFB 2.229/2024, emitido a partir de julho de 2026).
+package document
+
+import (
+	"strings"
+)
+
+// Kind é o tipo de documento.
+type Kind int
+
+const (
+	Unknown Kind = iota
+	CPF
+	CNPJ
+)
+
+func (k Kind) String() string {
+	switch k {
+	case CPF:
+		return "CPF"
+	case CNPJ:
+		return "CNPJ"
+	}
+	return "desconhecido"
+}
+
+// Normalize remove pontuação e espaços e passa letras para maiúsculas.
+// Caracteres fora de [0-9A-Z] (após a remoção de . / - e espaços) são mantidos
+// para que a validação os rejeite.
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(strings.TrimSpace(s)) {
+		switch r {
+		case '.', '/', '-', ' ', '\t':
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Detect identifica o tipo pelo formato (sem verificar os dígitos).
+func Detect(s string) Kind {
+	n := Normalize(s)
+	switch {
+	case len(n) == 11 && isDigits(n):
+		return CPF
+	case len(n) == 14 && isAlnum(n[:12]) && isDigits(n[12:]):
+		return CNPJ
+	}
+	return Unknown
+}
+
+// Validate devolve o tipo e se os dígitos verificadores conferem.
+func Validate(s string) (Kind, bool) {
+	n := Normalize(s)
+	switch Detect(n) {
+	case CPF:
+		return CPF, ValidCPF(n)
+	case CNPJ:
+		return CNPJ, ValidCNPJ(n)
+	}
+	return Unknown, false
+}
+
+// ValidCPF confere os dois dígitos verificadores (módulo 11).
+func ValidCPF(s string) bool {
+	n := Normalize(s)
+	if len(n) != 11 || !isDigits(n) || allSame(n) {
+		return false
+	}
+	for _, size := range []int{9, 10} {
+		sum := 0
+		for i := 0; i < size; i++ {
+			sum += int(n[i]-'0') * (size + 1 - i)
+		}
+		dv := sum * 10 % 11
+		if dv == 10 {
+			dv = 0
+		}
+		if int(n[size]-'0') != dv {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidCNPJ confere os dígitos verificadores do CNPJ numérico ou alfanumérico.
+// No alfanumérico cada caractere vale seu código ASCII menos 48 ('0'=0, 'A'=17).
+func ValidCNPJ(s string) bool {
+	n := Normalize(s)
+	if len(n) != 14 || !isAlnum(n[:12]) || !isDigits(n[12:]) || allSame(n) {
+		return false
+	}
+	for _, size := range []int{12, 13} {
+		sum, w := 0, 2
+		for i := size - 1; i >= 0; i-- {
+			sum += int(n[i]-'0') * w
+			if w++; w > 9 {
+				w = 2
+			}
+		}
+		dv := 0
+		if r := sum % 11; r >= 2 {
+			dv = 11 - r
+		}
+		if int(n[size]-'0') != dv {
+			return false
+		}
+	}
+	return true
+}
+
+// Format aplica a máscara (000.000.000-00 ou 00.000.000/0000-00); entradas
+// que não têm o formato esperado voltam normalizadas.
+func Format(s string) string {
+	n := Normalize(s)
+	switch Detect(n) {
+	case CPF:
+		return n[:3] + "." + n[3:6] + "." + n[6:9] + "-" + n[9:]
+	case CNPJ:
+		return n[:2] + "." + n[2:5] + "." + n[5:8] + "/" + n[8:12] + "-" + n[12:]
+	}
+	return n
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func isAlnum(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if !(c >= '0' && c <= '9') && !(c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+func allSame(s string) bool {
+	return strings.Count(s, s[:1]) == len(s)
+}
@@ -0,0 +1,81 @@
+package document
+
+import "testing"
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		in    string
+		kind  Kind
+		valid bool
+	}{
+		// CPF
+		{"529.982.247-25", CPF, true},
+		{"52998224725", CPF, true},
+		{"111.444.777-35", CPF, true},
+		{"123.456.789-09", CPF, true},
+		{"529.982.247-24", CPF, false},
+		{"123.456.789-00", CPF, false},
+		{"111.111.111-11", CPF, false}, // sequência repetida passa no módulo 11
+		{"000.000.000-00", CPF, false},
+		// CNPJ numérico
+		{"11.222.333/0001-81", CNPJ, true},
+		{"11222333000181", CNPJ, true},
+		{"00.000.000/0001-91", CNPJ, true},
+		{"11.444.777/0001-61", CNPJ, true},
+		{"11.222.333/0001-82", CNPJ, false},
+		{"11.111.111/1111-11", CNPJ, false},
+		// CNPJ alfanumérico (exemplo da Receita Federal)
+		{"12.ABC.345/01DE-35", CNPJ, true},
+		{"12ABC34501DE35", CNPJ, true},
+		{"12abc34501de35", CNPJ, true},
+		{"12.ABC.345/01DE-36", CNPJ, false},
+		{"12.ABC.345/01DE-3A", Unknown, false}, // DV é sempre numérico
+		// formato desconhecido
+		{"", Unknown, false},
+		{"5299822472", Unknown, false},
+		{"529.982.247-255", Unknown, false},
+		{"12.AB#.345/01DE-35", Unknown, false},
+	}
+	for _, c := range cases {
+		kind, valid := Validate(c.in)
+		if kind != c.kind || valid != c.valid {
+			t.Errorf("Validate(%q) = %v, %v; want %v, %v", c.in, kind, valid, c.kind, c.valid)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{" 529.982.247-25 ", "52998224725"},
+		{"12.abc.345/01de-35", "12ABC34501DE35"},
+		{"11 222 333\t0001 81", "11222333000181"},
+		{"12#34", "12#34"},
+	}
+	for _, c := range cases {
+		if got := Normalize(c.in); got != c.want {
+			t.Errorf("Normalize(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"52998224725", "529.982.247-25"},
+		{"11222333000181", "11.222.333/0001-81"},
+		{"12abc34501de35", "12.ABC.345/01DE-35"},
+		{"1234", "1234"},
+	}
+	for _, c := range cases {
+		if got := Format(c.in); got != c.want {
+			t.Errorf("Format(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestKindString(t *testing.T) {
+	for k, want := range map[Kind]string{CPF: "CPF", CNPJ: "CNPJ", Unknown: "desconhecido"} {
+		if got := k.String(); got != want {
+			t.Errorf("Kind(%d).String() = %q, want %q", k, got, want)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package flow
+
+import (
+	"context"
+
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/scheduler"
+)
+
+// StartScheduler registra os jobs do agente (follow-ups, campanhas, esperas de fluxo,
+// expurgo de transcrições) e roda o
+// scheduler até o contexto terminar. Seguro com várias réplicas (lease por job).
+func StartScheduler(ctx context.Context, cfg config.Config) {
+	sch := scheduler.Default()
+	sch.Handle(followUpKind, func(ctx context.Context, sch *scheduler.Scheduler, j scheduler.Job) error {
+		return runFollowUp(ctx, cfg, sch, j)
+	})
+	sch.Handle(campaignKind, func(ctx context.Context, sch *scheduler.Scheduler, j scheduler.Job) error {
+		return runCampaign(ctx, cfg, sch, j)
+	})
+	sch.Handle(flowWaitKind, func(ctx context.Context, sch *scheduler.Scheduler, j scheduler.Job) error {
+		return runFlowWait(ctx, cfg, sch, j)
+	})
+	sch.Handle(transcriptPurgeKind, runTranscriptPurge)
+	scheduleTranscriptPurge(ctx, sch)
+	go sch.Run(ctx)
+}
+
+// jobData guarda no job o necessário para reabrir a sessão fora do webhook.
+// O token da instância não é gravado: só se a origem tinha um ("instance_auth"),
+// para ser resolvido na execução.
+func jobData(s *Session) map[string]string {
+	d := map[string]string{
+		"instance_id": s.Opts.InstanceID,
+		"org_id":      s.Opts.OrgID,
+		"flow_id":     s.Opts.FlowID,
+		"slug":        s.Opts.Slug,
+		"number":      s.Number,
+		"name":        s.LeadName,
+	}
+	if s.Opts.InstanceToken != "" {
+		d["instance_auth"] = "1"
+	}
+	return d
+}
+
+// jobOptions reconstrói as opções e o número gravados por jobData; o token da
+// instância é sempre resolvido pelo id.
+func jobOptions(cfg config.Config, j scheduler.Job) (Options, string, error) {
+	o := Options{
+		InstanceID: j.Data["instance_id"],
+		OrgID:      j.Data["org_id"],
+		FlowID:     j.Data["flow_id"],
+		Slug:       j.Data["slug"],
+	}
+	o, err := withInstanceToken(cfg, o, j.Data["instance_auth"] == "1")
+	return o, j.Data["number"], err
+}
@@ -0,0 +1,222 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/campaign"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/optout"
+	"pac-lead-agent/internal/phone"
+	"pac-lead-agent/internal/scheduler"
+	"pac-lead-agent/internal/state"
+	"pac-lead-agent/internal/whatsfmt"
+)
+
+const campaignKind = "campaign"
+
+// campaignNoteTTL é por quanto tempo a resposta do lead ainda é tratada como
+// resposta à campanha.
+const campaignNoteTTL = 3 * 24 * time.Hour
+
+// CampaignNote é o contexto da campanha enviada ao lead.
+type CampaignNote struct {
+	Name       string    `json:"name,omitempty"`
+	Text       string    `json:"text"`
+	ProductIDs []string  `json:"product_ids,omitempty"`
+	SentAt     time.Time `json:"sent_at"`
+}
+
+// instructions descreve a campanha para o run do assistente ("" se não há
+// campanha recente).
+func (n *CampaignNote) instructions(now time.Time) string {
+	if n == nil || now.Sub(n.SentAt) > campaignNoteTTL {
+		return ""
+	}
+	note := "A loja enviou ao cliente uma mensagem de campanha"
+	if n.Name != "" {
+		note += fmt.Sprintf(" (%s)", n.Name)
+	}
+	note += "; ele pode estar respondendo a ela. Texto enviado:\n" + n.Text
+	if len(n.ProductIDs) > 0 {
+		note += "\nProdutos apresentados (IDs): " + strings.Join(n.ProductIDs, ", ")
+	}
+	return note
+}
+
+// campaignBudget é a folga antes do fim do lease: a campanha para, grava o
+// progresso e é retomada na próxima varredura (pode ser em outra réplica).
+const campaignBudget = time.Minute
+
+// StartCampaign valida, persiste e enfileira a campanha; o envio começa em
+// segundo plano e segue pelo scheduler.
+func StartCampaign(ctx context.Context, c campaign.Campaign) (campaign.Campaign, error) {
+	if err := c.Validate(); err != nil {
+		return c, err
+	}
+	c = campaign.New(c)
+	RememberInstance(c.InstanceID, c.InstanceToken)
+	st := state.Default()
+	if err := campaign.Save(ctx, st, c); err != nil {
+		return c, err
+	}
+	sch := scheduler.Default()
+	if err := sch.Schedule(ctx, scheduler.Job{Kind: campaignKind, ID: c.ID, DueAt: time.Now(), Since: c.CreatedAt}); err != nil {
+		return c, err
+	}
+	go sch.RunNow(context.Background(), campaignKind, c.ID)
+	return c, nil
+}
+
+// CancelCampaign interrompe os envios pendentes.
+func CancelCampaign(ctx context.Context, id string) (campaign.Campaign, error) {
+	st := state.Default()
+	c, err := campaign.Load(ctx, st, id)
+	if err != nil {
+		return c, err
+	}
+	if c.Status != campaign.StatusDone {
+		if err := campaign.Cancel(ctx, st, id); err != nil {
+			return c, err
+		}
+		c.Status = campaign.StatusCancelled
+	}
+	_ = scheduler.Default().Cancel(ctx, campaignKind, id)
+	return c, nil
+}
+
+func runCampaign(ctx context.Context, cfg config.Config, sch *scheduler.Scheduler, j scheduler.Job) error {
+	st := state.Default()
+	for {
+		cancelled, err := campaign.Cancelled(ctx, st, j.ID)
+		if err != nil {
+			return err
+		}
+		if cancelled {
+			return sch.Cancel(ctx, j.Kind, j.ID)
+		}
+		c, err := campaign.Load(ctx, st, j.ID)
+		if errors.Is(err, campaign.ErrNotFound) || c.Status == campaign.StatusCancelled || c.Status == campaign.StatusDone {
+			return sch.Cancel(ctx, j.Kind, j.ID)
+		}
+		if err != nil {
+			return err
+		}
+		i := c.Next()
+		if i < 0 {
+			c.Status = campaign.StatusDone
+			if err := campaign.Save(ctx, st, c); err != nil {
+				return err
+			}
+			return sch.Cancel(ctx, j.Kind, j.ID)
+		}
+		// Perto do fim do lease: devolve para a próxima varredura
+		if dl, ok := ctx.Deadline(); ok && time.Until(dl) < campaignBudget {
+			j.DueAt = time.Now()
+			return sch.Schedule(ctx, j)
+		}
+		if c.Status != campaign.StatusRunning {
+			_ = updateCampaign(ctx, st, c.ID, func(c *campaign.Campaign) {
+				if c.Status != campaign.StatusCancelled && c.Status != campaign.StatusDone {
+					c.Status = campaign.StatusRunning
+				}
+			})
+		}
+
+		// token resolvido pelo id (não é persistido); sem ele, tenta mais tarde
+		o, err := withInstanceToken(cfg, Options{InstanceID: c.InstanceID, OrgID: c.OrgID, FlowID: c.FlowID}, c.HasToken)
+		if err != nil {
+			return err
+		}
+		err = campaign.Throttle(ctx, st, c.InstanceID,
+			time.Duration(c.MinInterval)*time.Second, time.Duration(c.MaxInterval)*time.Second)
+		if err != nil {
+			j.DueAt = time.Now()
+			return sch.Schedule(context.Background(), j)
+		}
+
+		// A campanha pode ter sido cancelada durante a espera
+		if cancelled, err = campaign.Cancelled(ctx, st, j.ID); err != nil || cancelled {
+			continue
+		}
+		r := c.Recipients[i]
+		status, err := sendCampaignMessage(ctx, cfg, o, c, r)
+		r.Status = status
+		if err != nil {
+			r.Error = err.Error()
+		}
+		if status == campaign.StatusSent {
+			now := time.Now().UTC()
+			r.SentAt = &now
+		}
+		// Grava só o destinatário sobre a versão atual (o cancelamento tem
+		// chave própria e não é desfeito por esta gravação)
+		err = updateCampaign(ctx, st, c.ID, func(c *campaign.Campaign) {
+			if i < len(c.Recipients) {
+				c.Recipients[i] = r
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// updateCampaign relê a campanha, aplica fn e grava. O intervalo entre a
+// leitura e a gravação é curto (sem envio no meio); cancelamentos não passam
+// por aqui (campaign.Cancel).
+func updateCampaign(ctx context.Context, st state.Store, id string, fn func(*campaign.Campaign)) error {
+	c, err := campaign.Load(ctx, st, id)
+	if err != nil {
+		return err
+	}
+	fn(&c)
+	return campaign.Save(ctx, st, c)
+}
+
+// sendCampaignMessage envia a mensagem (e o carrossel, se houver) a um
+// destinatário e guarda o contexto da campanha na conversa, para o assistente
+// saber a que o lead está respondendo.
+func sendCampaignMessage(ctx context.Context, cfg config.Config, o Options, c campaign.Campaign, r campaign.Recipient) (string, error) {
+	number := phone.Canonical(r.Number)
+	if len(number) < 10 {
+		return campaign.StatusSkipped, fmt.Errorf("número inválido: %q", r.Number)
+	}
+	// Opt-out é conferido antes de criar lead/thread para quem pediu para sair
+	if tn, _, _, _ := (Contact{Opts: o, Number: number}).resolve(ctx, cfg); optout.Default().Is(ctx, tn.CNPJ, number) {
+		return campaign.StatusSkipped, errors.New("opt-out")
+	}
+	ctx = withTenantContext(ctx, o.OrgID, o.FlowID, o.InstanceID)
+
+	release := lockConversation(ctx, state.Default(), o.OrgID+":"+number)
+	defer release()
+
+	s, _, _, err := NewSession(ctx, cfg, o, number, r.Name)
+	if err != nil {
+		return campaign.StatusFailed, err
+	}
+	if s.LoadConversation(ctx).inHandoff() {
+		return campaign.StatusSkipped, errors.New("conversa com atendente humano")
+	}
+	if r.Name == "" {
+		r.Name = s.LeadName
+	}
+	text := whatsfmt.FromMarkdown(campaign.Render(c.Template, r))
+	if err := s.Whats.SendText(ctx, number, text); err != nil {
+		return campaign.StatusFailed, err
+	}
+	if len(c.ProductIDs) > 0 {
+		_ = SendProductsCarousel(ctx, s, c.ProductIDs)
+	}
+
+	// Fora da thread: como mensagem do assistente, seria lida como a última
+	// resposta (LastMessageText) nos reenvios, no áudio e nos follow-ups.
+	// Relida depois do carrossel, que grava os produtos pendentes.
+	conv := s.LoadConversation(ctx)
+	conv.Campaign = &CampaignNote{Name: c.Name, Text: text, ProductIDs: c.ProductIDs, SentAt: time.Now().UTC()}
+	_ = s.SaveConversation(ctx, conv)
+	return campaign.StatusSent, nil
+}
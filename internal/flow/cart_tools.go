@@ -0,0 +1,239 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pac-lead-agent/internal/cart"
+	"pac-lead-agent/internal/crm"
+)
+
+func init() {
+	registerTool(Tool{
+		Name:        "cart_add",
+		Description: "Adiciona um produto do catálogo ao carrinho do cliente. Use o ID do produto (o mesmo do ID_P).",
+		Parameters: objectSchema(map[string]any{
+			"product_id": map[string]any{"type": "string", "description": "ID do produto no catálogo"},
+			"quantity":   map[string]any{"type": "integer", "minimum": 1},
+			"variant":    map[string]any{"type": "string", "description": "Variação escolhida (ex.: tamanho/cor), se houver"},
+		}, "product_id"),
+		Handler: toolCartAdd,
+	})
+	registerTool(Tool{
+		Name:        "cart_remove",
+		Description: "Remove um produto (ou parte da quantidade) do carrinho.",
+		Parameters: objectSchema(map[string]any{
+			"product_id": map[string]any{"type": "string"},
+			"quantity":   map[string]any{"type": "integer", "description": "Quantidade a remover; omita para remover o item"},
+			"variant":    map[string]any{"type": "string"},
+		}, "product_id"),
+		Handler: toolCartRemove,
+	})
+	registerTool(Tool{
+		Name:        "cart_show",
+		Description: "Envia ao cliente o resumo do carrinho com totais e devolve o mesmo resumo.",
+		Parameters:  objectSchema(map[string]any{}),
+		Handler:     toolCartShow,
+	})
+	registerTool(Tool{
+		Name:        "cart_clear",
+		Description: "Esvazia o carrinho do cliente.",
+		Parameters:  objectSchema(map[string]any{}),
+		Handler:     toolCartClear,
+	})
+	registerTool(Tool{
+		Name:        "order_confirm",
+		Description: "Confirma o pedido com os itens do carrinho e gera o rascunho do pedido. Use somente depois que o cliente confirmar explicitamente.",
+		Parameters: objectSchema(map[string]any{
+			"customer_name":  map[string]any{"type": "string", "description": "Nome do cliente, se conhecido"},
+			"payment_method": map[string]any{"type": "string", "enum": []string{"pix", "outro"}, "description": "Forma de pagamento escolhida"},
+		}),
+		Handler: toolOrderConfirm,
+	})
+}
+
+type cartArgs struct {
+	ProductID     string `json:"product_id"`
+	Quantity      int    `json:"quantity"`
+	Variant       string `json:"variant"`
+	CustomerName  string `json:"customer_name"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+func toolCartAdd(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a cartArgs
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	item, err := catalogItem(ctx, s, a.ProductID, a.Variant, a.Quantity)
+	if err != nil {
+		return "", err
+	}
+	conv := s.LoadConversation(ctx)
+	wasEmpty := conv.Cart.Empty()
+	conv.ChangeCart(func(c *cart.Cart) bool { c.Add(item); return true })
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return "", err
+	}
+	cartCreated(s, wasEmpty, conv)
+	return cartResult(conv.Cart, fmt.Sprintf("Adicionado: %dx %s", item.Quantity, item.Name)), nil
+}
+
+func toolCartRemove(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a cartArgs
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	conv := s.LoadConversation(ctx)
+	removed := conv.ChangeCart(func(c *cart.Cart) bool {
+		return c.Remove(strings.TrimSpace(a.ProductID), strings.TrimSpace(a.Variant), a.Quantity)
+	})
+	if !removed {
+		return cartResult(conv.Cart, "Produto não estava no carrinho"), nil
+	}
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return "", err
+	}
+	return cartResult(conv.Cart, "Removido"), nil
+}
+
+func toolCartShow(ctx context.Context, s *Session, _ json.RawMessage) (string, error) {
+	conv := s.LoadConversation(ctx)
+	if err := s.Whats.SendText(ctx, s.Number, conv.Cart.Summary()); err != nil {
+		return "", err
+	}
+	return cartResult(conv.Cart, "Resumo já enviado ao cliente; não repita a lista, apenas continue a conversa."), nil
+}
+
+func toolCartClear(ctx context.Context, s *Session, _ json.RawMessage) (string, error) {
+	conv := s.LoadConversation(ctx)
+	conv.ChangeCart(func(c *cart.Cart) bool { c.Clear(); return true })
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return "", err
+	}
+	return cartResult(conv.Cart, "Carrinho esvaziado"), nil
+}
+
+func toolOrderConfirm(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a cartArgs
+	_ = json.Unmarshal(raw, &a)
+	draft, err := ConfirmOrder(ctx, s, strings.TrimSpace(a.CustomerName))
+	if err != nil {
+		return "", err
+	}
+	res := map[string]any{
+		"ok":       true,
+		"order_id": draft.ID,
+		"total":    draft.Total.String(),
+		"message":  "Pedido registrado como rascunho. Informe o número do pedido e siga para pagamento/entrega.",
+	}
+	// Etapa de pagamento: com Pix escolhido e configurado, a cobrança já sai aqui
+	if strings.EqualFold(a.PaymentMethod, "pix") {
+		if _, err := SendPixCharge(ctx, s, draft.Total, strings.TrimPrefix(draft.ID, "PED-")); err != nil {
+			res["pix_error"] = err.Error()
+		} else {
+			res["message"] = "Pedido registrado e cobrança Pix (QR Code + copia e cola) já enviada ao cliente."
+		}
+	}
+	b, _ := json.Marshal(res)
+	return string(b), nil
+}
+
+// ConfirmOrder congela o carrinho em um rascunho de pedido, envia ao endpoint de
+// pedidos (settings "order_endpoint" ou ORDER_DRAFT_URL) e guarda na conversa.
+func ConfirmOrder(ctx context.Context, s *Session, customer string) (cart.OrderDraft, error) {
+	conv := s.LoadConversation(ctx)
+	if conv.Cart.Empty() {
+		return cart.OrderDraft{}, errors.New("carrinho vazio: adicione produtos antes de confirmar")
+	}
+	draft := cart.NewOrderDraft(conv.Cart, s.Tenant.CNPJ, s.Tenant.OrgID, s.Number, customer)
+	draft.Document = conv.CustomerDocument
+	endpoint := s.Tenant.Settings.String("order_endpoint")
+	if endpoint == "" {
+		endpoint = s.Cfg.OrderDraftURL
+	}
+	if _, err := s.PL.PostOrderDraft(ctx, endpoint, draft); err != nil {
+		return cart.OrderDraft{}, err
+	}
+	conv.LastOrder = &draft
+	conv.ChangeCart(func(c *cart.Cart) bool { c.Clear(); return true })
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return cart.OrderDraft{}, err
+	}
+	emitCRM(s, crm.EventOrderPlaced, draft.ID, map[string]any{
+		"order_id": draft.ID,
+		"total":    draft.Total.Reais(),
+		"items":    len(draft.Items),
+	})
+	return draft, nil
+}
+
+// catalogItem busca o produto no catálogo e captura nome, preço e SKU atuais.
+// Produtos com variações exigem a variação escolhida e disponível.
+func catalogItem(ctx context.Context, s *Session, productID, variant string, qty int) (cart.Item, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return cart.Item{}, errors.New("product_id obrigatório")
+	}
+	p, err := fetchProduct(ctx, s, productID)
+	if err != nil {
+		return cart.Item{}, err
+	}
+	if !p.Available() {
+		return cart.Item{}, fmt.Errorf("produto %s (%s) está sem estoque", productID, p.Name)
+	}
+	item := cart.Item{
+		ProductID: productID,
+		Name:      p.Name,
+		Quantity:  qty,
+		UnitPrice: p.Price,
+		WeightKg:  p.WeightKg,
+	}
+	if len(p.Variants) == 0 {
+		item.Variant = strings.TrimSpace(variant)
+		return item, nil
+	}
+	avail := p.AvailableVariants()
+	v, ok := p.FindVariant(variant)
+	if strings.TrimSpace(variant) == "" && len(avail) == 1 {
+		v, ok = avail[0], true
+	}
+	if !ok || !v.Available() {
+		labels := make([]string, 0, len(avail))
+		for _, a := range avail {
+			labels = append(labels, a.Label())
+		}
+		return cart.Item{}, fmt.Errorf("informe uma variação disponível de %s: %s", p.Name, strings.Join(labels, ", "))
+	}
+	item.SKU, item.Variant, item.UnitPrice = v.SKU, v.Label(), p.PriceFor(v)
+	return item, nil
+}
+
+// cartResult é a saída padrão das tools de carrinho para o assistente.
+func cartResult(c cart.Cart, msg string) string {
+	items := make([]map[string]any, 0, len(c.Items))
+	for _, it := range c.Items {
+		items = append(items, map[string]any{
+			"product_id": it.ProductID,
+			"name":       it.Name,
+			"variant":    it.Variant,
+			"quantity":   it.Quantity,
+			"unit_price": it.UnitPrice.String(),
+			"total":      it.Total().String(),
+		})
+	}
+	res := map[string]any{
+		"message":  msg,
+		"items":    items,
+		"subtotal": c.Subtotal().String(),
+		"total":    c.Total().String(),
+	}
+	if c.Shipping != nil {
+		res["shipping"] = map[string]any{"option": c.Shipping.Name, "price": c.Shipping.Price.String(), "delivery": c.Shipping.Delivery}
+	}
+	b, _ := json.Marshal(res)
+	return string(b)
+}
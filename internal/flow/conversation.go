@@ -0,0 +1,69 @@
+package flow
+
+import (
+	"context"
+	"time"
+
+	"pac-lead-agent/internal/cart"
+	"pac-lead-agent/internal/flowdef"
+	"pac-lead-agent/internal/shipping"
+)
+
+// conversationTTL mantém o estado de conversas inativas por uma semana.
+const conversationTTL = 7 * 24 * time.Hour
+
+// Conversation é o estado persistido por conversa (tenant + número).
+type Conversation struct {
+	// PendingProducts são IDs que não couberam no último carrossel ("Ver mais").
+	PendingProducts []string         `json:"pending_products,omitempty"`
+	Cart            cart.Cart        `json:"cart"`
+	LastOrder       *cart.OrderDraft `json:"last_order,omitempty"`
+	// ShippingQuote é a última cotação de frete (para shipping_choose).
+	ShippingQuote []shipping.Option `json:"shipping_quote,omitempty"`
+	// CustomerDocument é o CPF/CNPJ validado do cliente (normalizado).
+	CustomerDocument string `json:"customer_document,omitempty"`
+	// HandoffUntil pausa o bot enquanto um atendente humano conduz a conversa.
+	HandoffUntil time.Time `json:"handoff_until,omitempty"`
+	// MenuShownAt permite escolher a opção do menu digitando o número.
+	MenuShownAt time.Time `json:"menu_shown_at,omitempty"`
+	// AwayNotifiedAt é o último aviso de ausência (fora do horário).
+	AwayNotifiedAt time.Time `json:"away_notified_at,omitempty"`
+	// Campaign é a última campanha enviada ao lead; vai para o assistente como
+	// instrução do run, não como mensagem da thread.
+	Campaign *CampaignNote `json:"campaign,omitempty"`
+	// Flow é a execução do fluxo do tenant (nó atual e respostas coletadas).
+	Flow      *flowdef.State `json:"flow,omitempty"`
+	UpdatedAt time.Time      `json:"updated_at"`
+}
+
+// ChangeCart aplica change ao carrinho. Se algo mudou, descarta também a
+// cotação de frete, que valia para os itens anteriores; toda mudança de itens
+// passa por aqui.
+func (c *Conversation) ChangeCart(change func(*cart.Cart) bool) bool {
+	if !change(&c.Cart) {
+		return false
+	}
+	c.ShippingQuote = nil
+	return true
+}
+
+func conversationKey(tn Tenant, number string) string {
+	return "conv:" + tn.CNPJ + ":" + number
+}
+
+// LoadConversation devolve o estado salvo (ou vazio se não existir).
+func (s *Session) LoadConversation(ctx context.Context) Conversation {
+	var c Conversation
+	if s.Store != nil {
+		_, _ = s.Store.Get(ctx, conversationKey(s.Tenant, s.Number), &c)
+	}
+	return c
+}
+
+func (s *Session) SaveConversation(ctx context.Context, c Conversation) error {
+	if s.Store == nil {
+		return nil
+	}
+	c.UpdatedAt = time.Now()
+	return s.Store.Set(ctx, conversationKey(s.Tenant, s.Number), c, conversationTTL)
+}
@@ -0,0 +1,37 @@
+package flow
+
+import (
+	"pac-lead-agent/internal/crm"
+)
+
+// crmMapping lê o mapeamento de campos do tenant (settings "crm_mapping").
+func crmMapping(s Settings) crm.Mapping {
+	var m crm.Mapping
+	_ = s.Decode("crm_mapping", &m)
+	return m
+}
+
+// emitCRM enfileira um marco da conversa para o CRM. ref diferencia ocorrências
+// do mesmo tipo (vazio = uma vez por lead).
+func emitCRM(s *Session, t crm.EventType, ref string, data map[string]any) {
+	crm.Default().Emit(s.PL, crmMapping(s.Tenant.Settings), crm.Event{
+		Type:   t,
+		CNPJ:   s.Tenant.CNPJ,
+		OrgID:  s.Tenant.OrgID,
+		Number: s.Number,
+		Name:   s.LeadName,
+		Ref:    ref,
+		Data:   data,
+	})
+}
+
+// cartCreated emite o evento quando o carrinho deixa de estar vazio.
+func cartCreated(s *Session, wasEmpty bool, conv Conversation) {
+	if !wasEmpty || conv.Cart.Empty() {
+		return
+	}
+	emitCRM(s, crm.EventCartCreated, conv.Cart.UpdatedAt.Format("20060102150405"), map[string]any{
+		"items": len(conv.Cart.Items),
+		"total": conv.Cart.Total().Reais(),
+	})
+}
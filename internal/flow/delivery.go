@@ -0,0 +1,90 @@
+package flow
+
+import (
+	"context"
+	"time"
+	"unicode/utf8"
+
+	"pac-lead-agent/internal/whatsfmt"
+)
+
+// DeliverySettings vem de settings["delivery"] do tenant e controla o envio
+// "humano": tamanho das mensagens, digitação e confirmação de leitura.
+type DeliverySettings struct {
+	// ChunkChars é o tamanho alvo de cada mensagem (padrão 500; máx. 4096).
+	ChunkChars int `json:"chunk_chars"`
+	// Typing liga o "digitando..." antes de cada mensagem (padrão ligado).
+	Typing *bool `json:"typing"`
+	// MsPerChar, MinDelayMs e MaxDelayMs definem a pausa de digitação.
+	MsPerChar  int `json:"ms_per_char"`
+	MinDelayMs int `json:"min_delay_ms"`
+	MaxDelayMs int `json:"max_delay_ms"`
+	// ReadReceipts marca a mensagem do lead como lida (padrão ligado).
+	ReadReceipts *bool `json:"read_receipts"`
+}
+
+func deliverySettings(st Settings) DeliverySettings {
+	var d DeliverySettings
+	st.Decode("delivery", &d)
+	if d.ChunkChars <= 0 {
+		d.ChunkChars = 500
+	}
+	if d.MsPerChar <= 0 {
+		d.MsPerChar = 35
+	}
+	if d.MinDelayMs <= 0 {
+		d.MinDelayMs = 1200
+	}
+	if d.MaxDelayMs < d.MinDelayMs {
+		d.MaxDelayMs = 6000
+		if d.MaxDelayMs < d.MinDelayMs {
+			d.MaxDelayMs = d.MinDelayMs
+		}
+	}
+	return d
+}
+
+func (d DeliverySettings) typing() bool       { return d.Typing == nil || *d.Typing }
+func (d DeliverySettings) readReceipts() bool { return d.ReadReceipts == nil || *d.ReadReceipts }
+
+// typingDelay é proporcional ao tamanho da mensagem, dentro dos limites.
+func (d DeliverySettings) typingDelay(text string) time.Duration {
+	ms := utf8.RuneCountInString(text) * d.MsPerChar
+	if ms < d.MinDelayMs {
+		ms = d.MinDelayMs
+	}
+	if ms > d.MaxDelayMs {
+		ms = d.MaxDelayMs
+	}
+	return time.Duration(ms) * time.Millisecond
+}
+
+// sendReply envia um texto do assistente (Markdown convertido para a
+// formatação do WhatsApp) dividido em mensagens de tamanho natural, com
+// "digitando..." e pausa proporcional antes de cada uma.
+func sendReply(ctx context.Context, s *Session, text string) error {
+	d := deliverySettings(s.Tenant.Settings)
+	for _, chunk := range whatsfmt.Split(whatsfmt.FromMarkdown(text), d.ChunkChars) {
+		if d.typing() {
+			delay := d.typingDelay(chunk)
+			_ = s.Whats.SendPresence(ctx, s.Number, "composing", int(delay/time.Millisecond))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+		if err := s.Whats.SendText(ctx, s.Number, chunk); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// markRead confirma a leitura da mensagem recebida, se o tenant permitir.
+func markRead(ctx context.Context, s *Session, messageID string) {
+	if messageID == "" || !deliverySettings(s.Tenant.Settings).readReceipts() {
+		return
+	}
+	_ = s.Whats.MarkRead(ctx, messageID)
+}
@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"strings"
 
-	"pac-lead-agent/internal/clients"
 	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/crm"
+	"pac-lead-agent/internal/leads"
+	"pac-lead-agent/internal/phone"
+	"pac-lead-agent/internal/state"
 	"pac-lead-agent/internal/types"
 )
 
@@ -46,86 +49,185 @@ func HandleIncomingMessage(ctx context.Context, cfg config.Config, in types.Inco
 
 	// (ADICIONADO) injeta org/flow/instância no contexto para utilização pelos layers internos
 	ctx = withTenantContext(ctx, o.OrgID, o.FlowID, o.InstanceID)
+	// guarda o token da instância (só em memória) para follow-ups e esperas de fluxo
+	RememberInstance(o.InstanceID, o.InstanceToken)
 
-	// escolhe o token da instância se vier do header; caso contrário, usa o global do cfg
-	token := cfg.UAzapiToken
-	if strings.TrimSpace(o.InstanceToken) != "" {
-		token = o.InstanceToken
+	// Recibos de entrega/leitura só atualizam a transcrição
+	if isStatusUpdate(in) {
+		return Response{Ok: true}, handleStatusUpdate(ctx, in)
 	}
 
-	whats := clients.NewWhats(cfg.UAzapiBaseURL, token)
-	ai := clients.NewOpenAI(cfg.OpenAIKey, cfg.OpenAIAssistantID)
-	pl := clients.NewPacLead(cfg.PacLeadBaseURL, cfg.PacLeadCRMBaseURL, cfg.PlatformBaseURL)
-	// (ADICIONADO) cliente dedicado da Plataforma para buscar configurações do agente
-	plat := clients.NewPlatform(cfg.PlatformBaseURL)
-
+	// Grupos não são atendidos pelo agente
+	if phone.ParseJID(in.Body.Message.ChatID).Kind == phone.Group {
+		return Response{Ok: true}, nil
+	}
 	number := extractNumber(in.Body.Message.ChatID)
+	if number == "" {
+		return Response{}, fmt.Errorf("missing chatId")
+	}
 	text := strings.TrimSpace(in.Body.Message.Content)
 	msgType := strings.ToLower(in.Body.Message.Type)
 
-	// Ajusta CNPJ (multi-tenant) via settings; fallback mantém constante
-	cnpj := "23820015000100"
-	if settings, err := plat.GetAgentSettings(ctx, o.OrgID, o.FlowID); err == nil && settings != nil {
-		if v, ok := settings["tax_id"].(string); ok && strings.TrimSpace(v) != "" {
-			cnpj = onlyDigits(v)
+	// Uma mensagem por vez por conversa (webhooks simultâneos disputam a mesma thread)
+	release := lockConversation(ctx, state.Default(), o.OrgID+":"+number)
+	defer release()
+
+	sess, lead, created, err := NewSession(ctx, cfg, o, number, in.Body.Message.SenderName)
+	if err != nil {
+		return Response{}, err
+	}
+	whats := sess.Whats
+	recordInbound(ctx, sess, in.Body.Message, msgType, text)
+
+	// Acompanhamento do lead: data/prévia/contadores a cada mensagem (gravação agrupada)
+	leads.Default().Record(ctx, sess.PL, leads.Event{Lead: lead, Inbound: true, Name: in.Body.Message.SenderName, Preview: inboundPreview(msgType, text)})
+	if created {
+		emitCRM(sess, crm.EventNewLead, "", map[string]any{"thread_id": sess.ThreadID})
+	}
+	// O lead respondeu: follow-ups pendentes perdem o sentido
+	cancelFollowUp(ctx, sess)
+
+	// Opt-out ("SAIR", "PARE"): registra e confirma; números descadastrados não recebem resposta
+	if ok, err := handleOptOut(ctx, sess, text); ok {
+		if err != nil {
+			return Response{}, err
 		}
-	} else {
-		// (ADICIONADO) fallback para cliente PacLead existente (compatibilidade)
-		if settings, err2 := pl.GetAgentSettings(ctx, o.OrgID, o.FlowID); err2 == nil && settings != nil {
-			if v, ok := settings["tax_id"].(string); ok && strings.TrimSpace(v) != "" {
-				cnpj = onlyDigits(v)
-			}
+		return Response{Ok: true}, nil
+	}
+
+	// Atendimento humano em andamento: o bot não responde
+	if sess.LoadConversation(ctx).inHandoff() {
+		return Response{Ok: true}, nil
+	}
+	markRead(ctx, sess, in.Body.Message.ID)
+
+	// Fora do horário com modo "away": só a mensagem de ausência
+	if ok, err := handleOffline(ctx, sess); ok {
+		if err != nil {
+			return Response{}, err
 		}
+		return Response{Ok: true}, nil
 	}
 
-	// Obtém prompt final (DEFAULT + customizações do cliente)
-	prompt, _ := BuildPrompt(ctx, cfg, pl, o.OrgID, o.FlowID)
+	// Menu inicial do tenant (antes da IA): primeira mensagem, palavra-chave ou escolha digitada
+	if ok, err := HandleMenu(ctx, sess, created, text); ok {
+		if err != nil {
+			return Response{}, err
+		}
+		return Response{Ok: true}, nil
+	}
 
-	threadID, err := EnsureThread(ctx, ai, pl, number, cnpj)
-	if err != nil {
-		return Response{}, err
+	// Botões com payload estruturado (carrossel): intenção explícita. Payload
+	// de outro tenant segue como texto, igual a um ID não reconhecido
+	if p, ok := ParseButtonPayload(in.Body.Message.ButtonID); ok && isButtonReply(msgType) && p.ForTenant(sess.Tenant.CNPJ) {
+		if err := HandleButtonIntent(ctx, sess, p); err != nil {
+			return Response{}, err
+		}
+		return Response{Ok: true}, nil
 	}
 
 	switch msgType {
-	case "text", "conversation", "extendedtextmessage", "templatebuttonreplymessage":
-		if text != "" {
+	case "text", "conversation", "extendedtextmessage", "templatebuttonreplymessage",
+		"buttonsresponsemessage", "listresponsemessage", "interactiveresponsemessage":
+		if text != "" || in.Body.Message.ButtonID != "" {
+			// "Ver mais": envia a próxima página do último carrossel
+			if isMoreRequest(text) || in.Body.Message.ButtonID == moreButtonID {
+				if ok, _ := SendNextProductsPage(ctx, sess); ok {
+					return Response{Ok: true}, nil
+				}
+			}
+
 			// Se mensagem do usuário já veio com "ID_P:" envia carrossel de produtos direto
 			if ids := parseIDs(strings.ToUpper(text)); len(ids) > 0 {
 				_ = whats.SendText(ctx, number, "Procurando produtos…")
-				_ = SendProductsCarousel(ctx, pl, whats, cnpj, number, ids)
+				_ = SendProductsCarousel(ctx, sess, ids)
 				return Response{Ok: true}, nil
 			}
 
-			// Envia mensagem do usuário e cria run com 'instructions' = prompt final
-			if err := SendUserTextAndRunWithInstructions(ctx, ai, threadID, text, prompt); err == nil {
-				if reply, _ := GetLastAssistantText(ctx, ai, threadID); reply != "" {
-					// Se a resposta do assistente contiver "ID_P:", enviamos o carrossel
-					if ids := parseIDs(strings.ToUpper(reply)); len(ids) > 0 {
-						_ = whats.SendText(ctx, number, "Separei alguns produtos para você 👇")
-						_ = SendProductsCarousel(ctx, pl, whats, cnpj, number, ids)
-					} else {
-						_ = whats.SendText(ctx, number, reply)
-					}
-				}
+			if text == "" {
+				text = in.Body.Message.ButtonID
 			}
+			// Fluxo do tenant (padrão: um único nó de IA)
+			_ = RunFlow(ctx, sess, text)
 		}
 	case "image":
 		// Ponto de entrada para visão — por enquanto responde texto
 		_ = whats.SendText(ctx, number, "📸 Recebi a imagem! Vou analisar e já retorno.")
 	case "audio", "audiomessage", "ptt":
-		// Responde com áudio da última mensagem do assistente
-		_ = SendAssistantReplyAudio(ctx, ai, whats, threadID, number)
+		sess.InboundAudio = true
+		// Gateways com transcrição mandam o texto em content
+		if text != "" {
+			_ = RunFlow(ctx, sess, text)
+			break
+		}
+		// Sem transcrição: responde com a última mensagem do assistente
+		if sess.wantsAudio() {
+			if reply, _ := GetLastAssistantText(ctx, sess.AI, sess.ThreadID); reply != "" {
+				_ = sendAudioReply(ctx, sess, reply)
+			}
+		}
 	default:
 		_ = whats.SendText(ctx, number, fmt.Sprintf("Tipo de mensagem não suportado ainda: %s", msgType))
 	}
 	return Response{Ok: true}, nil
 }
 
-func extractNumber(chatid string) string {
-	if i := strings.IndexByte(chatid, '@'); i > 0 {
-		return chatid[:i]
+// replyWithAssistant envia o texto ao assistente (com o prompt do tenant como
+// instructions e as tools registradas), aguarda o run, entrega a resposta e
+// agenda os follow-ups de silêncio.
+func replyWithAssistant(ctx context.Context, s *Session, text string) error {
+	if err := runAssistant(ctx, s, text); err != nil {
+		return err
+	}
+	qualifyAsync(s)
+	reply, _ := GetLastAssistantText(ctx, s.AI, s.ThreadID)
+	if reply == "" {
+		return nil
+	}
+	if err := deliverReply(ctx, s, reply); err != nil {
+		return err
 	}
-	return chatid
+	// Conta o silêncio do lead a partir desta resposta
+	scheduleFollowUp(ctx, s)
+	return nil
+}
+
+// deliverReply envia a resposta do assistente ao lead: carrossel se vier "ID_P:", texto caso contrário.
+func deliverReply(ctx context.Context, s *Session, reply string) error {
+	// Se a resposta do assistente contiver "ID_P:", enviamos o carrossel
+	if ids := parseIDs(strings.ToUpper(reply)); len(ids) > 0 {
+		_ = s.Whats.SendText(ctx, s.Number, "Separei alguns produtos para você 👇")
+		return SendProductsCarousel(ctx, s, ids)
+	}
+	// Resposta em voz conforme a política do tenant; se o TTS falhar, vai texto
+	if s.wantsAudio() {
+		if err := sendAudioReply(ctx, s, reply); err == nil {
+			return nil
+		}
+	}
+	return sendReply(ctx, s, reply)
+}
+
+// inboundPreview resume a mensagem recebida para o registro do lead.
+func inboundPreview(msgType, text string) string {
+	if text != "" {
+		return text
+	}
+	return "[" + msgType + "]"
+}
+
+// outboundPreview resume o que foi enviado ao lead a partir do corpo da requisição.
+func outboundPreview(path string, body map[string]any) string {
+	if t, ok := body["text"].(string); ok && t != "" {
+		return t
+	}
+	return "[" + strings.TrimPrefix(path, "/send/") + "]"
+}
+
+// extractNumber devolve a chave canônica do contato (E.164 sem "+", com nono
+// dígito), a mesma usada para lead, estado e lock da conversa.
+func extractNumber(chatid string) string {
+	return phone.ParseJID(chatid).Key()
 }
 
 // Helper: parse ids from a string like "ID_P: 1, 2, 3"
@@ -149,14 +251,3 @@ func parseIDs(s string) []string {
 	}
 	return out
 }
-
-// onlyDigits remove qualquer caractere não numérico (útil para CPF/CNPJ)
-func onlyDigits(s string) string {
-	var b strings.Builder
-	for _, r := range s {
-		if r >= '0' && r <= '9' {
-			b.WriteRune(r)
-		}
-	}
-	return b.String()
-}
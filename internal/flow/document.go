@@ -0,0 +1,43 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+
+	"pac-lead-agent/internal/document"
+)
+
+func init() {
+	registerTool(Tool{
+		Name: "customer_document",
+		Description: "Valida o CPF ou CNPJ informado pelo cliente e, se válido, registra para o pedido/nota. " +
+			"Se inválido, peça para o cliente conferir o número.",
+		Parameters: objectSchema(map[string]any{
+			"document": map[string]any{"type": "string", "description": "CPF ou CNPJ como o cliente digitou"},
+		}, "document"),
+		Handler: toolCustomerDocument,
+	})
+}
+
+func toolCustomerDocument(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		Document string `json:"document"`
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	kind, ok := document.Validate(a.Document)
+	res := map[string]any{"valid": ok, "kind": kind.String()}
+	if ok {
+		conv := s.LoadConversation(ctx)
+		conv.CustomerDocument = document.Normalize(a.Document)
+		if err := s.SaveConversation(ctx, conv); err != nil {
+			return "", err
+		}
+		res["formatted"] = document.Format(a.Document)
+	} else {
+		res["message"] = "Documento inválido: os dígitos verificadores não conferem."
+	}
+	b, _ := json.Marshal(res)
+	return string(b), nil
+}
@@ -0,0 +1,340 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/flowdef"
+	"pac-lead-agent/internal/scheduler"
+	"pac-lead-agent/internal/state"
+)
+
+const flowWaitKind = "flowwait"
+
+// errFlowUnavailable adia a espera do fluxo quando a definição não pôde ser lida.
+var errFlowUnavailable = errors.New("definição do fluxo indisponível")
+
+// maxFlowSteps evita laços infinitos em grafos mal configurados.
+const maxFlowSteps = 25
+
+// lastInputVar guarda a última mensagem do lead (decisões por intenção).
+const lastInputVar = "_last"
+
+// flowCache evita buscar a definição na Plataforma a cada mensagem.
+var flowCache = newFlowDefCache(time.Minute, 256)
+
+// flowLastGood guarda a última definição buscada com sucesso, usada quando a
+// Plataforma falha (sem ela o fluxo cairia no padrão e zeraria as conversas).
+var flowLastGood = newFlowDefCache(24*time.Hour, 256)
+
+// loadFlow busca o fluxo do tenant pelo FlowID (Plataforma, depois
+// settings["flow"]). Sem fluxo, ou com fluxo inválido, usa o fluxo padrão de
+// um único nó de IA. ok é false quando a Plataforma falhou e não há cópia
+// anterior: o chamador não deve mexer no estado do fluxo.
+func loadFlow(ctx context.Context, s *Session) (def *flowdef.Definition, ok bool) {
+	key := s.Opts.OrgID + ":" + s.Opts.FlowID
+	if def, ok := flowCache.get(key); ok {
+		return def, true
+	}
+	var (
+		raw []byte
+		err error
+	)
+	if s.Opts.FlowID != "" && s.Cfg.PlatformBaseURL != "" {
+		raw, err = clients.NewPlatform(s.Cfg.PlatformBaseURL).GetFlow(ctx, s.Opts.OrgID, s.Opts.FlowID)
+	}
+	if err != nil {
+		// Falha temporária: não guarda no cache; usa a última cópia boa
+		log.Println("flow: buscar definição:", err, "flow:", s.Opts.FlowID)
+		return flowLastGood.get(key)
+	}
+	if raw == nil {
+		if v, ok := s.Tenant.Settings["flow"]; ok && v != nil {
+			raw, _ = json.Marshal(v)
+		}
+	}
+	def = parseFlow(raw, s.Opts.FlowID)
+	flowCache.set(key, def)
+	flowLastGood.set(key, def)
+	return def, true
+}
+
+// parseFlow decodifica e valida a definição; vazia ou inválida vira o fluxo padrão.
+func parseFlow(raw []byte, flowID string) *flowdef.Definition {
+	if len(raw) == 0 {
+		return flowdef.Default()
+	}
+	var d flowdef.Definition
+	if err := json.Unmarshal(raw, &d); err != nil {
+		log.Println("flow: definição:", err, "flow:", flowID)
+		return flowdef.Default()
+	}
+	if d.ID == "" {
+		d.ID = flowID
+	}
+	if err := d.Validate(); err != nil {
+		log.Println("flow:", err, "flow:", d.ID)
+		return flowdef.Default()
+	}
+	return &d
+}
+
+// RunFlow conduz a mensagem do lead pelo fluxo do tenant, a partir do nó em
+// que a conversa parou. Fluxo encerrado segue na IA; sem a definição (falha
+// na Plataforma) a mensagem vai para a IA e o estado do fluxo é mantido.
+func RunFlow(ctx context.Context, s *Session, text string) error {
+	def, ok := loadFlow(ctx, s)
+	if !ok {
+		return replyWithAssistant(ctx, s, text)
+	}
+	st := s.LoadConversation(ctx).Flow
+	if !st.Matches(def) {
+		st = flowdef.NewState(def)
+	}
+	if st.Done {
+		return replyWithAssistant(ctx, s, text)
+	}
+	// Em espera: a mensagem vai para a IA e a espera continua
+	if !st.WaitUntil.IsZero() && time.Now().Before(st.WaitUntil) {
+		return replyWithAssistant(ctx, s, text)
+	}
+	st.Vars[lastInputVar] = text
+	err := runSteps(ctx, s, def, st, &text)
+	saveFlowState(ctx, s, st)
+	return err
+}
+
+// saveFlowState relê a conversa (os nós podem ter mexido no carrinho/paginação)
+// antes de gravar o estado do fluxo.
+func saveFlowState(ctx context.Context, s *Session, st *flowdef.State) {
+	conv := s.LoadConversation(ctx)
+	conv.Flow = st
+	_ = s.SaveConversation(ctx, conv)
+}
+
+// runSteps executa nós até precisar de uma resposta do lead, entrar em espera
+// ou encerrar. input é a mensagem ainda não consumida (nil se já usada).
+func runSteps(ctx context.Context, s *Session, def *flowdef.Definition, st *flowdef.State, input *string) error {
+	advance := func(next string) {
+		if next == "" {
+			st.Done = true
+			return
+		}
+		st.Node = next
+	}
+	for i := 0; i < maxFlowSteps && !st.Done; i++ {
+		n := def.Nodes[st.Node]
+		if n == nil {
+			st.Done = true
+			return nil
+		}
+		switch n.Type {
+		case flowdef.NodeMessage:
+			if err := sendReply(ctx, s, flowdef.Render(n.Text, st.Vars)); err != nil {
+				return err
+			}
+			advance(n.Next)
+
+		case flowdef.NodeQuestion:
+			if !st.Awaiting {
+				st.Awaiting = true
+				return askQuestion(ctx, s, n, st)
+			}
+			if input == nil {
+				return nil
+			}
+			if !n.Accepts(*input) {
+				// Esgotadas as tentativas, o fluxo termina e a IA assume
+				if st.Retries++; st.Retries > n.MaxRetries() {
+					st.Done, st.Awaiting, st.Retries = true, false, 0
+					return replyWithAssistant(ctx, s, *input)
+				}
+				retry := n.Retry
+				if retry == "" {
+					retry = "Não consegui entender essa resposta. " + n.Text
+				}
+				return sendReply(ctx, s, flowdef.Render(retry, st.Vars))
+			}
+			if n.Variable != "" {
+				st.Vars[n.Variable] = strings.TrimSpace(*input)
+			}
+			st.Awaiting, st.Retries, input = false, 0, nil
+			advance(n.Next)
+
+		case flowdef.NodeBranch:
+			next := pickBranch(ctx, s, n, st)
+			if next == "" {
+				next = n.Default
+			}
+			advance(next)
+
+		case flowdef.NodeAI:
+			prompt := flowdef.Render(n.Prompt, st.Vars)
+			switch {
+			case input != nil && prompt != "":
+				if err := replyWithAssistant(ctx, s, "[Instrução do fluxo] "+prompt+"\n\n"+*input); err != nil {
+					return err
+				}
+			case input != nil:
+				if err := replyWithAssistant(ctx, s, *input); err != nil {
+					return err
+				}
+			case prompt != "":
+				if err := replyWithAssistant(ctx, s, "[Fluxo] "+prompt); err != nil {
+					return err
+				}
+			}
+			input = nil
+			// Sem próximo nó a conversa fica na IA
+			if n.Next == "" {
+				return nil
+			}
+			advance(n.Next)
+
+		case flowdef.NodeProducts:
+			if err := SendProductsCarousel(ctx, s, n.ProductIDs); err != nil {
+				return err
+			}
+			advance(n.Next)
+
+		case flowdef.NodeHandoff:
+			st.Done = true
+			return StartHandoff(ctx, s, flowdef.Render(n.Reason, st.Vars))
+
+		case flowdef.NodeWait:
+			if st.WaitUntil.IsZero() {
+				st.WaitUntil = time.Now().Add(time.Duration(n.Seconds) * time.Second)
+				return scheduler.Default().Schedule(ctx, scheduler.Job{
+					Kind: flowWaitKind, ID: followUpID(s), DueAt: st.WaitUntil, Since: time.Now(), Data: jobData(s),
+				})
+			}
+			if time.Now().Before(st.WaitUntil) {
+				return nil
+			}
+			st.WaitUntil = time.Time{}
+			advance(n.Next)
+
+		case flowdef.NodeEnd:
+			st.Done = true
+		}
+	}
+	if !st.Done {
+		// Limite de passos (Validate já barra laços sem pergunta): encerra o
+		// fluxo e deixa a conversa com a IA
+		log.Println("flow: limite de passos atingido, flow:", def.ID, "nó:", st.Node)
+		st.Done = true
+		if input != nil {
+			return replyWithAssistant(ctx, s, *input)
+		}
+	}
+	return nil
+}
+
+// askQuestion envia a pergunta, com botões quando há até 3 opções.
+func askQuestion(ctx context.Context, s *Session, n *flowdef.Node, st *flowdef.State) error {
+	text := flowdef.Render(n.Text, st.Vars)
+	if len(n.Choices) == 0 || len(n.Choices) > 3 {
+		if len(n.Choices) > 3 {
+			text += "\n\n" + strings.Join(n.Choices, "\n")
+		}
+		return sendReply(ctx, s, text)
+	}
+	choices := make([]string, 0, len(n.Choices))
+	for _, c := range n.Choices {
+		choices = append(choices, c+"|"+c)
+	}
+	return s.Whats.SendButtons(ctx, s.Number, text, choices)
+}
+
+// pickBranch devolve o destino do primeiro ramo que vale: condições sobre as
+// variáveis, palavras-chave na última mensagem ou, por fim, classificação da
+// intenção pelo modelo entre os ramos com Intent.
+func pickBranch(ctx context.Context, s *Session, n *flowdef.Node, st *flowdef.State) string {
+	last := st.Vars[lastInputVar]
+	var intents []string
+	for _, b := range n.Branches {
+		switch {
+		case len(b.If) > 0:
+			if flowdef.All(b.If, st.Vars) {
+				return b.Next
+			}
+		case len(b.Keywords) > 0 && flowdef.Keyword(last, b.Keywords):
+			return b.Next
+		}
+		if b.Intent != "" {
+			intents = append(intents, b.Intent)
+		}
+	}
+	if len(intents) == 0 || strings.TrimSpace(last) == "" {
+		return ""
+	}
+	intent := classifyIntent(ctx, s, last, intents)
+	for _, b := range n.Branches {
+		if b.Intent != "" && b.Intent == intent {
+			return b.Next
+		}
+	}
+	return ""
+}
+
+// classifyIntent pede ao modelo de extração a intenção da mensagem entre as opções.
+func classifyIntent(ctx context.Context, s *Session, text string, intents []string) string {
+	if s.Cfg.ExtractionModel == "" {
+		return ""
+	}
+	options := append(append([]string(nil), intents...), "nenhuma")
+	schema := objectSchema(map[string]any{"intent": map[string]any{"type": "string", "enum": options}}, "intent")
+	schema["additionalProperties"] = false
+	raw, err := s.AI.ChatJSON(ctx, s.Cfg.ExtractionModel,
+		"Classifique a intenção da mensagem de um cliente no WhatsApp entre as opções. Use \"nenhuma\" se nenhuma servir.",
+		text, "intent", schema)
+	if err != nil {
+		return ""
+	}
+	var out struct {
+		Intent string `json:"intent"`
+	}
+	_ = json.Unmarshal(raw, &out)
+	return out.Intent
+}
+
+// runFlowWait retoma um fluxo parado em um nó de espera.
+func runFlowWait(ctx context.Context, cfg config.Config, sch *scheduler.Scheduler, j scheduler.Job) error {
+	o, number, err := jobOptions(cfg, j)
+	if err != nil {
+		return err
+	}
+	ctx = withTenantContext(ctx, o.OrgID, o.FlowID, o.InstanceID)
+
+	release := lockConversation(ctx, state.Default(), o.OrgID+":"+number)
+	defer release()
+
+	s, _, _, err := NewSession(ctx, cfg, o, number, j.Data["name"])
+	if err != nil {
+		return err
+	}
+	def, ok := loadFlow(ctx, s)
+	if !ok {
+		return errFlowUnavailable
+	}
+	st := s.LoadConversation(ctx).Flow
+	if !st.Matches(def) || st.Done || st.WaitUntil.IsZero() || s.LoadConversation(ctx).inHandoff() || s.optedOut(ctx) {
+		return sch.Cancel(ctx, j.Kind, j.ID)
+	}
+	if time.Now().Before(st.WaitUntil) {
+		j.DueAt = st.WaitUntil
+		return sch.Schedule(ctx, j)
+	}
+	if err := sch.Cancel(ctx, j.Kind, j.ID); err != nil {
+		return err
+	}
+	err = runSteps(ctx, s, def, st, nil)
+	saveFlowState(ctx, s, st)
+	return err
+}
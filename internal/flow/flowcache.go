@@ -0,0 +1,61 @@
+package flow
+
+import (
+	"sync"
+	"time"
+
+	"pac-lead-agent/internal/flowdef"
+)
+
+// flowDefCache guarda definições de fluxo já validadas (somente leitura depois
+// de guardadas), com TTL e limite de itens.
+type flowDefCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]flowDefEntry
+}
+
+type flowDefEntry struct {
+	def     *flowdef.Definition
+	expires time.Time
+}
+
+func newFlowDefCache(ttl time.Duration, maxEntries int) *flowDefCache {
+	return &flowDefCache{ttl: ttl, max: maxEntries, entries: map[string]flowDefEntry{}}
+}
+
+func (c *flowDefCache) get(key string) (*flowdef.Definition, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return e.def, true
+}
+
+func (c *flowDefCache) set(key string, def *flowdef.Definition) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.max {
+		// remove os vencidos; se ainda cheio, o que vence primeiro
+		var first string
+		for k, e := range c.entries {
+			if now.After(e.expires) {
+				delete(c.entries, k)
+			} else if first == "" || e.expires.Before(c.entries[first].expires) {
+				first = k
+			}
+		}
+		if len(c.entries) >= c.max {
+			delete(c.entries, first)
+		}
+	}
+	c.entries[key] = flowDefEntry{def: def, expires: now.Add(c.ttl)}
+}
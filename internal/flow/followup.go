@@ -0,0 +1,200 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/leads"
+	"pac-lead-agent/internal/scheduler"
+	"pac-lead-agent/internal/state"
+)
+
+const followUpKind = "followup"
+
+const defaultFollowUpInstruction = "O cliente não responde há algum tempo. Escreva UMA mensagem curta e cordial de " +
+	"follow-up retomando o assunto da conversa (produtos vistos, carrinho, dúvidas). Não repita a mensagem anterior, " +
+	"não pressione e não invente promoções."
+
+// FollowUpSettings vem de settings["followup"] do tenant.
+type FollowUpSettings struct {
+	Enabled *bool `json:"enabled"`
+	// DelaysHours são as horas de silêncio (desde a última resposta do bot) de cada tentativa.
+	DelaysHours []float64 `json:"delays_hours"`
+	MaxAttempts int       `json:"max_attempts"`
+	QuietHours  struct {
+		Start string `json:"start"` // "21:00"
+		End   string `json:"end"`   // "08:00"
+	} `json:"quiet_hours"`
+	Timezone    string `json:"timezone"`
+	Instruction string `json:"instruction"`
+}
+
+func followUpSettings(st Settings) FollowUpSettings {
+	var f FollowUpSettings
+	st.Decode("followup", &f)
+	if len(f.DelaysHours) == 0 {
+		f.DelaysHours = []float64{2, 24, 72}
+	}
+	if f.MaxAttempts <= 0 || f.MaxAttempts > len(f.DelaysHours) {
+		f.MaxAttempts = len(f.DelaysHours)
+	}
+	if f.QuietHours.Start == "" && f.QuietHours.End == "" {
+		f.QuietHours.Start, f.QuietHours.End = "21:00", "08:00"
+	}
+	if strings.TrimSpace(f.Instruction) == "" {
+		f.Instruction = defaultFollowUpInstruction
+	}
+	return f
+}
+
+// enabled: ligado por padrão; o tenant desliga com followup.enabled=false.
+func (f FollowUpSettings) enabled() bool {
+	return f.Enabled == nil || *f.Enabled
+}
+
+func (f FollowUpSettings) location() *time.Location {
+	if f.Timezone != "" {
+		if loc, err := time.LoadLocation(f.Timezone); err == nil {
+			return loc
+		}
+	}
+	return leads.Location
+}
+
+// dueAt devolve quando a tentativa deve sair, empurrada para o fim do horário
+// de silêncio se cair dentro dele.
+func (f FollowUpSettings) dueAt(since time.Time, attempt int) time.Time {
+	t := since.Add(time.Duration(f.DelaysHours[attempt] * float64(time.Hour)))
+	return f.afterQuiet(t)
+}
+
+func (f FollowUpSettings) afterQuiet(t time.Time) time.Time {
+	start, ok1 := clockMinutes(f.QuietHours.Start)
+	end, ok2 := clockMinutes(f.QuietHours.End)
+	if !ok1 || !ok2 || start == end {
+		return t
+	}
+	local := t.In(f.location())
+	m := local.Hour()*60 + local.Minute()
+	quiet := m >= start && m < end
+	if start > end { // atravessa a meia-noite
+		quiet = m >= start || m < end
+	}
+	if !quiet {
+		return t
+	}
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, local.Location())
+	out := day.Add(time.Duration(end) * time.Minute)
+	if !out.After(local) {
+		out = day.AddDate(0, 0, 1).Add(time.Duration(end) * time.Minute)
+	}
+	return out
+}
+
+// clockMinutes converte "HH:MM" em minutos desde a meia-noite.
+func clockMinutes(s string) (int, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || hh > 23 || mm < 0 || mm > 59 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
+
+func followUpID(s *Session) string {
+	return s.Tenant.CNPJ + ":" + s.Number
+}
+
+// scheduleFollowUp (re)inicia a contagem de silêncio após uma resposta do bot.
+func scheduleFollowUp(ctx context.Context, s *Session) {
+	f := followUpSettings(s.Tenant.Settings)
+	if !f.enabled() || s.optedOut(ctx) {
+		return
+	}
+	now := time.Now()
+	_ = scheduler.Default().Schedule(ctx, scheduler.Job{
+		Kind:  followUpKind,
+		ID:    followUpID(s),
+		Since: now,
+		DueAt: f.dueAt(now, 0),
+		Data:  jobData(s),
+	})
+}
+
+// cancelFollowUp encerra os follow-ups pendentes: o lead respondeu.
+func cancelFollowUp(ctx context.Context, s *Session) {
+	_ = scheduler.Default().Cancel(ctx, followUpKind, followUpID(s))
+}
+
+func runFollowUp(ctx context.Context, cfg config.Config, sch *scheduler.Scheduler, j scheduler.Job) error {
+	o, number, err := jobOptions(cfg, j)
+	if err != nil {
+		return err
+	}
+	ctx = withTenantContext(ctx, o.OrgID, o.FlowID, o.InstanceID)
+
+	release := lockConversation(ctx, state.Default(), o.OrgID+":"+number)
+	defer release()
+
+	// O lead pode ter respondido (cancelado/reagendado) enquanto esperávamos o lock
+	cur, ok := sch.Get(ctx, j.Kind, j.ID)
+	if !ok || !cur.Since.Equal(j.Since) || cur.Attempt != j.Attempt {
+		return nil
+	}
+
+	s, _, _, err := NewSession(ctx, cfg, o, number, j.Data["name"])
+	if err != nil {
+		return err
+	}
+	f := followUpSettings(s.Tenant.Settings)
+	if !f.enabled() || j.Attempt >= f.MaxAttempts || s.LoadConversation(ctx).inHandoff() || s.optedOut(ctx) {
+		return sch.Cancel(ctx, j.Kind, j.ID)
+	}
+	// Horário de silêncio pode ter mudado desde o agendamento
+	if due := f.afterQuiet(time.Now()); due.After(time.Now()) {
+		j.DueAt = due
+		return sch.Schedule(ctx, j)
+	}
+
+	if err := sendFollowUp(ctx, s, f.Instruction); err != nil {
+		return err
+	}
+
+	j.Attempt++
+	if j.Attempt >= f.MaxAttempts {
+		return sch.Cancel(ctx, j.Kind, j.ID)
+	}
+	j.DueAt = f.dueAt(j.Since, j.Attempt)
+	return sch.Schedule(ctx, j)
+}
+
+// sendFollowUp cria um run sem mensagem do usuário, com a instrução de
+// follow-up, e entrega o texto gerado.
+func sendFollowUp(ctx context.Context, s *Session, instruction string) error {
+	before, _ := GetLastAssistantText(ctx, s.AI, s.ThreadID)
+	runID, err := s.AI.CreateRunWithOptions(ctx, s.ThreadID, clients.RunOptions{
+		Instructions:           s.instructions(),
+		AdditionalInstructions: instruction,
+		Tools:                  toolDefinitions(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := waitRun(ctx, s, runID); err != nil {
+		return err
+	}
+	reply, _ := GetLastAssistantText(ctx, s.AI, s.ThreadID)
+	if reply == "" || reply == before {
+		return fmt.Errorf("follow-up sem texto novo")
+	}
+	return deliverReply(ctx, s, reply)
+}
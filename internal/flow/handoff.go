@@ -0,0 +1,92 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/crm"
+	"pac-lead-agent/internal/hours"
+	"pac-lead-agent/internal/state"
+	"pac-lead-agent/internal/whatsfmt"
+)
+
+func init() {
+	registerTool(Tool{
+		Name: "human_handoff",
+		Description: "Transfere a conversa para um atendente humano (pedido explícito do cliente, reclamação, " +
+			"negociação fora da sua alçada). Depois disso o bot deixa de responder.",
+		Parameters: objectSchema(map[string]any{
+			"reason": map[string]any{"type": "string", "description": "Motivo resumido da transferência"},
+		}, "reason"),
+		Handler: toolHumanHandoff,
+	})
+}
+
+// StartHandoff pausa o bot na conversa por handoff_hours (padrão 12h), avisa o
+// lead e notifica o CRM. Fora do horário de atendimento a pausa começa na
+// próxima abertura e o lead é avisado de quando a equipe volta.
+func StartHandoff(ctx context.Context, s *Session, reason string) error {
+	pause := time.Duration(s.Tenant.Settings.Int(12, "handoff_hours")) * time.Hour
+	conv := s.LoadConversation(ctx)
+	// Fora do horário a pausa conta a partir da próxima abertura da equipe
+	start := time.Now()
+	st := s.hoursStatus()
+	if !st.Open && !st.NextOpen.IsZero() {
+		start = st.NextOpen
+	}
+	conv.HandoffUntil = start.Add(pause)
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return err
+	}
+	emitCRM(s, crm.EventHandoff, conv.HandoffUntil.Format(time.RFC3339), map[string]any{"reason": reason, "team_open": st.Open})
+	msg := s.Tenant.Settings.String("handoff_message")
+	switch {
+	case !st.Open && st.NextOpen.IsZero():
+		msg = "Nossa equipe está fora do horário agora. Deixei tudo registrado e vão falar com você assim que voltarem. 🙏"
+	case !st.Open:
+		msg = fmt.Sprintf("Nossa equipe está fora do horário agora e volta %s. Deixei tudo registrado e vão falar com você por aqui. 🙏",
+			hours.Describe(st.NextOpen, time.Now()))
+	case msg == "":
+		msg = "Vou chamar alguém da nossa equipe para continuar o seu atendimento. Já já te respondem por aqui! 🙌"
+	}
+	return s.Whats.SendText(ctx, s.Number, whatsfmt.FromMarkdown(msg))
+}
+
+// ReleaseHandoff devolve a conversa ao bot antes do fim da pausa (o atendente
+// terminou). Retorna false se a conversa não estava com um humano.
+func ReleaseHandoff(ctx context.Context, cfg config.Config, c Contact) (bool, error) {
+	tn, number, _, _ := c.resolve(ctx, cfg)
+	st := state.Default()
+	release := lockConversation(ctx, st, c.Opts.OrgID+":"+number)
+	defer release()
+
+	var conv Conversation
+	key := conversationKey(tn, number)
+	if ok, err := st.Get(ctx, key, &conv); err != nil || !ok || !conv.inHandoff() {
+		return false, err
+	}
+	conv.HandoffUntil = time.Time{}
+	if err := st.Set(ctx, key, conv, conversationTTL); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// inHandoff informa se um humano assumiu a conversa.
+func (c Conversation) inHandoff() bool {
+	return time.Now().Before(c.HandoffUntil)
+}
+
+func toolHumanHandoff(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal(raw, &a)
+	if err := StartHandoff(ctx, s, a.Reason); err != nil {
+		return "", err
+	}
+	return `{"ok":true,"message":"Cliente avisado da transferência. Não envie mais mensagens."}`, nil
+}
@@ -0,0 +1,96 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/hours"
+	"pac-lead-agent/internal/leads"
+)
+
+// Comportamento fora do horário (settings["business_hours"].offline_mode).
+const (
+	OfflineAway    = "away"    // envia a mensagem de ausência e não aciona a IA
+	OfflineReduced = "reduced" // IA atende, sem prometer atendimento humano imediato
+)
+
+// awayRepeat evita repetir a mensagem de ausência a cada mensagem do lead.
+const awayRepeat = 6 * time.Hour
+
+// BusinessHours vem de settings["business_hours"] do tenant.
+type BusinessHours struct {
+	hours.Schedule
+	OfflineMode string `json:"offline_mode"`
+	AwayMessage string `json:"away_message"`
+}
+
+func businessHours(st Settings) BusinessHours {
+	var b BusinessHours
+	st.Decode("business_hours", &b)
+	if b.OfflineMode != OfflineAway {
+		b.OfflineMode = OfflineReduced
+	}
+	return b
+}
+
+// hoursStatus é a situação do atendimento agora (aberto se não houver grade).
+func (s *Session) hoursStatus() hours.Status {
+	return businessHours(s.Tenant.Settings).At(time.Now(), leads.Location)
+}
+
+// instructions é o prompt do tenant com a situação do horário de atendimento.
+func (s *Session) instructions() string {
+	b := businessHours(s.Tenant.Settings)
+	if !b.Configured() {
+		return s.Prompt
+	}
+	now := time.Now()
+	st := b.At(now, leads.Location)
+	note := "\n\n**Horário de atendimento humano**\n- Agora: "
+	if st.Open {
+		note += "ABERTO."
+	} else {
+		note += fmt.Sprintf("FECHADO (%s).", st.Reason)
+		if next := hours.Describe(st.NextOpen, now); next != "" {
+			note += " Próxima abertura: " + next + "."
+		}
+		note += "\n- Não prometa retorno imediato de um atendente; informe quando a equipe volta."
+	}
+	return s.Prompt + note
+}
+
+// handleOffline aplica o modo "away": responde com a mensagem de ausência (no
+// máximo a cada awayRepeat) e devolve true para a IA não ser acionada.
+func handleOffline(ctx context.Context, s *Session) (bool, error) {
+	b := businessHours(s.Tenant.Settings)
+	if !b.Configured() || b.OfflineMode != OfflineAway {
+		return false, nil
+	}
+	now := time.Now()
+	st := b.At(now, leads.Location)
+	if st.Open {
+		return false, nil
+	}
+	conv := s.LoadConversation(ctx)
+	if now.Sub(conv.AwayNotifiedAt) < awayRepeat {
+		return true, nil
+	}
+	conv.AwayNotifiedAt = now
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return true, err
+	}
+	return true, sendReply(ctx, s, awayMessage(b, st, now))
+}
+
+func awayMessage(b BusinessHours, st hours.Status, now time.Time) string {
+	next := hours.Describe(st.NextOpen, now)
+	if msg := strings.TrimSpace(b.AwayMessage); msg != "" {
+		return strings.ReplaceAll(msg, "{{proxima_abertura}}", next)
+	}
+	if next == "" {
+		return "Olá! No momento estamos fora do horário de atendimento. Deixe sua mensagem que retornamos assim que possível."
+	}
+	return fmt.Sprintf("Olá! No momento estamos fora do horário de atendimento. Voltamos %s e respondemos sua mensagem assim que possível. 🙏", next)
+}
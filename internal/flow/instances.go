@@ -0,0 +1,49 @@
+package flow
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"pac-lead-agent/internal/config"
+)
+
+// errInstanceToken indica que o token da instância não está disponível nesta
+// réplica (o job é adiado e tentado de novo).
+var errInstanceToken = errors.New("token da instância indisponível")
+
+// instanceTokens guarda, só em memória, os tokens de instância vistos nos
+// webhooks e nas campanhas. Jobs e campanhas persistem apenas o id da
+// instância; o token é resolvido na execução.
+var instanceTokens sync.Map
+
+// RememberInstance registra o token de uma instância para jobs futuros.
+func RememberInstance(id, token string) {
+	if id = strings.TrimSpace(id); id != "" && strings.TrimSpace(token) != "" {
+		instanceTokens.Store(id, strings.TrimSpace(token))
+	}
+}
+
+// instanceToken resolve o token da instância: memória do processo e, depois,
+// UAZAPI_INSTANCE_TOKENS.
+func instanceToken(cfg config.Config, id string) (string, bool) {
+	if v, ok := instanceTokens.Load(id); ok {
+		return v.(string), true
+	}
+	t, ok := cfg.InstanceTokens[id]
+	return t, ok
+}
+
+// withInstanceToken completa as opções reabertas fora do webhook. needed diz
+// se a origem tinha token próprio (sem ele cai no UAZAPI_TOKEN global).
+func withInstanceToken(cfg config.Config, o Options, needed bool) (Options, error) {
+	if !needed || o.InstanceID == "" {
+		return o, nil
+	}
+	t, ok := instanceToken(cfg, o.InstanceID)
+	if !ok {
+		return o, errInstanceToken
+	}
+	o.InstanceToken = t
+	return o, nil
+}
@@ -0,0 +1,73 @@
+package flow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pac-lead-agent/internal/cart"
+	"pac-lead-agent/internal/catalog"
+)
+
+// HandleButtonIntent trata o toque em um botão com payload estruturado: registra
+// a escolha no carrinho e informa ao assistente exatamente o que foi escolhido,
+// sem depender de ele interpretar o texto livre do botão.
+// O chamador confere antes que o payload é do tenant da sessão (ForTenant).
+func HandleButtonIntent(ctx context.Context, s *Session, p ButtonPayload) error {
+	if p.Action == ActionMenu {
+		return SelectMenuOption(ctx, s, p.ProductID)
+	}
+	prod, err := fetchProduct(ctx, s, p.ProductID)
+	if errors.Is(err, errProductNotFound) {
+		return s.Whats.SendText(ctx, s.Number, "Não encontrei esse produto no catálogo agora. Pode me dizer qual você procura?")
+	}
+	if err != nil {
+		return err
+	}
+	if !prod.Available() {
+		return replyWithAssistant(ctx, s, fmt.Sprintf("[Seleção no carrossel] O cliente quis o produto ID %s (%s), "+
+			"mas ele está sem estoque. Avise com gentileza e sugira alternativas parecidas.", prod.ID, prod.Name))
+	}
+
+	switch p.Action {
+	case ActionBuy, ActionVariant:
+		var variant catalog.Variant
+		avail := prod.AvailableVariants()
+		switch {
+		case p.Variant != "":
+			v, ok := prod.FindVariant(p.Variant)
+			if !ok || !v.Available() {
+				_ = s.Whats.SendText(ctx, s.Number, "Essa opção acabou de esgotar 😕 Veja as que ainda temos:")
+				return SendVariantOptions(ctx, s, prod)
+			}
+			variant = v
+		case len(avail) > 1:
+			// Etapa de variação: o pedido precisa do SKU exato
+			return SendVariantOptions(ctx, s, prod)
+		case len(avail) == 1:
+			variant = avail[0]
+		}
+		item := cart.Item{ProductID: prod.ID, Name: prod.Name, Quantity: 1, UnitPrice: prod.PriceFor(variant), WeightKg: prod.WeightKg}
+		if variant.SKU != "" {
+			item.SKU, item.Variant = variant.SKU, variant.Label()
+		}
+		conv := s.LoadConversation(ctx)
+		wasEmpty := conv.Cart.Empty()
+		conv.ChangeCart(func(c *cart.Cart) bool { c.Add(item); return true })
+		if err := s.SaveConversation(ctx, conv); err != nil {
+			return err
+		}
+		cartCreated(s, wasEmpty, conv)
+		chosen := prod.Name
+		if item.Variant != "" {
+			chosen += " — " + item.Variant + " (SKU " + item.SKU + ")"
+		}
+		return replyWithAssistant(ctx, s, fmt.Sprintf("[Seleção no carrossel] O cliente escolheu o produto ID %s: %s, %s. "+
+			"O item já foi adicionado ao carrinho (quantidade 1). Confirme a escolha e conduza o fechamento do pedido.",
+			prod.ID, chosen, item.UnitPrice))
+	case ActionDetails:
+		return replyWithAssistant(ctx, s, fmt.Sprintf("[Seleção no carrossel] O cliente pediu mais detalhes do produto ID %s (%s). "+
+			"Apresente os detalhes e benefícios de forma breve.", prod.ID, prod.Name))
+	}
+	return fmt.Errorf("unknown button action %q", p.Action)
+}
@@ -0,0 +1,50 @@
+package flow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+const (
+	// lockLease cobre um run (até runTimeout) mais a entrega pausada da resposta.
+	lockLease = 5 * time.Minute
+	lockWait  = 45 * time.Second
+)
+
+// lockConversation serializa o processamento por conversa (inclusive entre
+// réplicas, com Redis). Espera até lockWait; se o lock não vier, segue mesmo
+// assim para não perder a mensagem. O lease expira sozinho se o processo cair.
+// O lock guarda um token aleatório e release só apaga se ele ainda for o dono
+// (quem seguiu sem o lock, ou perdeu o lease, não apaga o lock de outro).
+func lockConversation(ctx context.Context, st state.Store, key string) (release func()) {
+	k := "lock:conv:" + key
+	token := lockToken()
+	deadline := time.Now().Add(lockWait)
+	for {
+		ok, err := st.SetNX(ctx, k, token, lockLease)
+		if err != nil {
+			return func() {}
+		}
+		if ok {
+			return func() { _, _ = st.DeleteIf(context.Background(), k, token) }
+		}
+		if time.Now().After(deadline) {
+			return func() {}
+		}
+		select {
+		case <-ctx.Done():
+			return func() {}
+		case <-time.After(300 * time.Millisecond):
+		}
+	}
+}
+
+func lockToken() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
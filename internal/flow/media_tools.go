@@ -0,0 +1,159 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"pac-lead-agent/internal/clients"
+)
+
+func init() {
+	registerTool(Tool{
+		Name:        "send_product_sheet",
+		Description: "Envia ao cliente o PDF/ficha técnica de um produto do catálogo, quando existir.",
+		Parameters: objectSchema(map[string]any{
+			"product_id": map[string]any{"type": "string", "description": "ID do produto"},
+		}, "product_id"),
+		Handler: toolSendProductSheet,
+	})
+	registerTool(Tool{
+		Name: "send_media",
+		Description: "Envia um material da biblioteca da loja (catálogo em PDF, vídeo, tabela de medidas...). " +
+			"Se o nome não existir, a resposta lista os disponíveis.",
+		Parameters: objectSchema(map[string]any{
+			"name": map[string]any{"type": "string", "description": "Nome do material na biblioteca (ex.: catalogo)"},
+		}, "name"),
+		Handler: toolSendMedia,
+	})
+	registerTool(Tool{
+		Name:        "send_store_location",
+		Description: "Envia o pin de localização da loja.",
+		Parameters:  objectSchema(map[string]any{}),
+		Handler:     toolSendStoreLocation,
+	})
+	registerTool(Tool{
+		Name:        "send_contact_card",
+		Description: "Envia o cartão de contato do vendedor/loja.",
+		Parameters:  objectSchema(map[string]any{}),
+		Handler:     toolSendContactCard,
+	})
+}
+
+// MediaItem é uma entrada de settings["media_library"], por nome.
+type MediaItem struct {
+	Type     string `json:"type"` // image, video, document (padrão)
+	URL      string `json:"url"`
+	FileName string `json:"file_name"`
+	MimeType string `json:"mimetype"`
+	Caption  string `json:"caption"`
+}
+
+// SendMediaItem envia o material conforme o tipo.
+func SendMediaItem(ctx context.Context, s *Session, it MediaItem) error {
+	kind := strings.ToLower(strings.TrimSpace(it.Type))
+	switch kind {
+	case "image", "video", "document", "audio":
+	default:
+		kind = "document"
+	}
+	m := clients.Media{URL: it.URL, FileName: it.FileName, MimeType: it.MimeType}
+	return s.Whats.SendMedia(ctx, s.Number, kind, m, it.Caption)
+}
+
+func toolSendProductSheet(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		ProductID string `json:"product_id"`
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	p, err := fetchProduct(ctx, s, a.ProductID)
+	if err != nil {
+		return "", err
+	}
+	url := p.SpecSheetURL()
+	if url == "" {
+		return `{"ok":false,"message":"Este produto não tem ficha técnica cadastrada."}`, nil
+	}
+	name := p.Name
+	if name == "" {
+		name = "produto-" + p.ID
+	}
+	it := MediaItem{Type: "document", URL: url, FileName: name + ".pdf", MimeType: "application/pdf", Caption: "Ficha técnica: " + name}
+	if err := SendMediaItem(ctx, s, it); err != nil {
+		return "", err
+	}
+	return `{"ok":true,"message":"Ficha técnica enviada ao cliente."}`, nil
+}
+
+func toolSendMedia(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	var lib map[string]MediaItem
+	s.Tenant.Settings.Decode("media_library", &lib)
+	it, ok := lib[a.Name]
+	if !ok {
+		for k, v := range lib {
+			if strings.EqualFold(k, strings.TrimSpace(a.Name)) {
+				it, ok = v, true
+			}
+		}
+	}
+	if !ok || it.URL == "" {
+		names := make([]string, 0, len(lib))
+		for k := range lib {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+		b, _ := json.Marshal(map[string]any{"ok": false, "available": names})
+		return string(b), nil
+	}
+	if err := SendMediaItem(ctx, s, it); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`{"ok":true,"message":"%s enviado ao cliente."}`, it.Type), nil
+}
+
+func toolSendStoreLocation(ctx context.Context, s *Session, _ json.RawMessage) (string, error) {
+	var loc struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Name      string  `json:"name"`
+		Address   string  `json:"address"`
+	}
+	if !s.Tenant.Settings.Decode("store_location", &loc) || (loc.Latitude == 0 && loc.Longitude == 0) {
+		return `{"ok":false,"message":"A loja não cadastrou localização."}`, nil
+	}
+	err := s.Whats.SendLocation(ctx, s.Number, clients.Location(loc))
+	if err != nil {
+		return "", err
+	}
+	return `{"ok":true}`, nil
+}
+
+func toolSendContactCard(ctx context.Context, s *Session, _ json.RawMessage) (string, error) {
+	var c struct {
+		Name         string `json:"name"`
+		Phone        string `json:"phone"`
+		Organization string `json:"organization"`
+		Email        string `json:"email"`
+		URL          string `json:"url"`
+	}
+	if !s.Tenant.Settings.Decode("contact_card", &c) || c.Phone == "" {
+		return `{"ok":false,"message":"A loja não cadastrou contato."}`, nil
+	}
+	err := s.Whats.SendContact(ctx, s.Number, clients.Contact{
+		FullName: c.Name, Phone: c.Phone, Organization: c.Organization, Email: c.Email, URL: c.URL,
+	})
+	if err != nil {
+		return "", err
+	}
+	return `{"ok":true}`, nil
+}
@@ -0,0 +1,200 @@
+package flow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ações das opções de menu (settings["menu"].options[].action).
+const (
+	MenuCatalog = "catalog" // carrossel com product_ids (ou pede ao assistente o catálogo)
+	MenuHandoff = "handoff" // transfere para atendente humano
+	MenuAI      = "ai"      // entrega o prompt da opção ao assistente
+	MenuMessage = "message" // responde com um texto fixo
+)
+
+// menuReplyWindow é por quanto tempo "1", "2"... valem como escolha do menu.
+const menuReplyWindow = 30 * time.Minute
+
+// MenuOption é uma opção do menu inicial do tenant.
+type MenuOption struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Action      string   `json:"action"`
+	ProductIDs  []string `json:"product_ids"`
+	// Prompt é o texto enviado ao assistente (ação "ai"); vazio = título da opção.
+	Prompt  string `json:"prompt"`
+	Message string `json:"message"`
+}
+
+// MenuSettings vem de settings["menu"] do tenant.
+type MenuSettings struct {
+	Enabled bool   `json:"enabled"`
+	Text    string `json:"text"`
+	// Type força "button" ou "list"; vazio = botões até 3 opções, lista acima.
+	Type       string       `json:"type"`
+	ButtonText string       `json:"button_text"`
+	Keywords   []string     `json:"keywords"`
+	OnFirst    *bool        `json:"show_on_first_message"`
+	Options    []MenuOption `json:"options"`
+}
+
+func menuSettings(st Settings) MenuSettings {
+	var m MenuSettings
+	st.Decode("menu", &m)
+	if m.Text == "" {
+		m.Text = "Olá! Como posso te ajudar?"
+	}
+	if m.ButtonText == "" {
+		m.ButtonText = "Ver opções"
+	}
+	if len(m.Keywords) == 0 {
+		m.Keywords = []string{"menu", "inicio", "início", "opções", "opcoes"}
+	}
+	for i := range m.Options {
+		if m.Options[i].ID == "" {
+			m.Options[i].ID = strconv.Itoa(i + 1)
+		}
+	}
+	return m
+}
+
+func (m MenuSettings) active() bool  { return m.Enabled && len(m.Options) > 0 }
+func (m MenuSettings) onFirst() bool { return m.OnFirst == nil || *m.OnFirst }
+
+func (m MenuSettings) useList() bool {
+	return m.Type == "list" || (m.Type != "button" && len(m.Options) > 3)
+}
+
+func normalizeChoice(s string) string {
+	return strings.Trim(strings.ToLower(strings.TrimSpace(s)), ".!?)- ")
+}
+
+func (m MenuSettings) isKeyword(text string) bool {
+	t := normalizeChoice(text)
+	for _, k := range m.Keywords {
+		if t != "" && t == normalizeChoice(k) {
+			return true
+		}
+	}
+	return false
+}
+
+// pick reconhece a opção digitada: número ("2"), id ou título.
+func (m MenuSettings) pick(text string) (MenuOption, bool) {
+	t := normalizeChoice(text)
+	if t == "" {
+		return MenuOption{}, false
+	}
+	if n, err := strconv.Atoi(t); err == nil && n >= 1 && n <= len(m.Options) {
+		return m.Options[n-1], true
+	}
+	for _, o := range m.Options {
+		if t == normalizeChoice(o.ID) || t == normalizeChoice(o.Title) {
+			return o, true
+		}
+	}
+	return MenuOption{}, false
+}
+
+func (m MenuSettings) find(id string) (MenuOption, bool) {
+	for _, o := range m.Options {
+		if o.ID == id {
+			return o, true
+		}
+	}
+	return MenuOption{}, false
+}
+
+// HandleMenu aplica o menu inicial antes da IA: mostra o menu na primeira
+// mensagem ou por palavra-chave e roteia a escolha digitada. Devolve true se a
+// mensagem foi tratada aqui.
+func HandleMenu(ctx context.Context, s *Session, firstMessage bool, text string) (bool, error) {
+	m := menuSettings(s.Tenant.Settings)
+	if !m.active() {
+		return false, nil
+	}
+	if m.isKeyword(text) || (firstMessage && m.onFirst()) {
+		return true, SendMenu(ctx, s)
+	}
+	conv := s.LoadConversation(ctx)
+	if conv.MenuShownAt.IsZero() || time.Since(conv.MenuShownAt) > menuReplyWindow {
+		return false, nil
+	}
+	o, ok := m.pick(text)
+	if !ok {
+		return false, nil
+	}
+	return true, runMenuOption(ctx, s, o)
+}
+
+// SendMenu envia o menu como botões ou lista, numerado para quem preferir digitar.
+func SendMenu(ctx context.Context, s *Session) error {
+	m := menuSettings(s.Tenant.Settings)
+	if !m.active() {
+		return nil
+	}
+	list := m.useList()
+	choices := make([]string, 0, len(m.Options)+1)
+	if list {
+		choices = append(choices, "[Opções]")
+	}
+	for i, o := range m.Options {
+		id := ButtonPayload{Action: ActionMenu, Tenant: s.Tenant.CNPJ, ProductID: o.ID}.Encode()
+		choice := fmt.Sprintf("%d - %s|%s", i+1, o.Title, id)
+		if list && o.Description != "" {
+			choice += "|" + o.Description
+		}
+		choices = append(choices, choice)
+	}
+	var err error
+	if list {
+		err = s.Whats.SendList(ctx, s.Number, m.Text, m.ButtonText, choices)
+	} else {
+		err = s.Whats.SendButtons(ctx, s.Number, m.Text, choices)
+	}
+	if err != nil {
+		return err
+	}
+	conv := s.LoadConversation(ctx)
+	conv.MenuShownAt = time.Now()
+	return s.SaveConversation(ctx, conv)
+}
+
+// SelectMenuOption executa a opção tocada (botão/lista) pelo id.
+func SelectMenuOption(ctx context.Context, s *Session, id string) error {
+	o, ok := menuSettings(s.Tenant.Settings).find(id)
+	if !ok {
+		return SendMenu(ctx, s)
+	}
+	return runMenuOption(ctx, s, o)
+}
+
+func runMenuOption(ctx context.Context, s *Session, o MenuOption) error {
+	conv := s.LoadConversation(ctx)
+	conv.MenuShownAt = time.Time{}
+	_ = s.SaveConversation(ctx, conv)
+
+	switch o.Action {
+	case MenuCatalog:
+		if len(o.ProductIDs) > 0 {
+			return SendProductsCarousel(ctx, s, o.ProductIDs)
+		}
+		return replyWithAssistant(ctx, s, "[Menu] O cliente escolheu ver o catálogo. Mostre os produtos mais relevantes.")
+	case MenuHandoff:
+		return StartHandoff(ctx, s, "menu: "+o.Title)
+	case MenuMessage:
+		if o.Message != "" {
+			return sendReply(ctx, s, o.Message)
+		}
+	}
+	prompt := o.Prompt
+	if prompt == "" {
+		prompt = o.Title
+	}
+	return replyWithAssistant(ctx, s, prompt)
+}
@@ -0,0 +1,70 @@
+package flow
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Ações codificadas nos IDs de botões enviados pelo bot.
+const (
+	ActionBuy     = "buy"     // "Vou querer": adiciona ao carrinho
+	ActionDetails = "details" // pede mais detalhes do produto
+	ActionVariant = "variant" // escolha de tamanho/cor (SKU) após "Vou querer"
+	ActionMenu    = "menu"    // opção do menu inicial (ProductID leva o id da opção)
+)
+
+// payloadPrefix marca IDs de botão gerados por este serviço. O separador é ":"
+// porque "|" já separa texto e id nas choices do gateway.
+const payloadPrefix = "pl"
+
+// ButtonPayload é o conteúdo estruturado de um botão: ação, tenant (CNPJ),
+// produto e, opcionalmente, a variação (SKU).
+type ButtonPayload struct {
+	Action    string
+	Tenant    string
+	ProductID string
+	Variant   string
+}
+
+// Encode gera o ID do botão, ex.: "pl:buy:23820015000100:123" ou
+// "pl:variant:23820015000100:123:SKU-M-AZUL".
+func (p ButtonPayload) Encode() string {
+	parts := []string{payloadPrefix, p.Action, p.Tenant, p.ProductID}
+	if p.Variant != "" {
+		parts = append(parts, url.QueryEscape(p.Variant))
+	}
+	return strings.Join(parts, ":")
+}
+
+// ParseButtonPayload interpreta um ID gerado por Encode.
+func ParseButtonPayload(s string) (ButtonPayload, bool) {
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) < 4 || len(parts) > 5 || parts[0] != payloadPrefix || parts[1] == "" || parts[3] == "" {
+		return ButtonPayload{}, false
+	}
+	p := ButtonPayload{Action: parts[1], Tenant: parts[2], ProductID: parts[3]}
+	if len(parts) == 5 {
+		v, err := url.QueryUnescape(parts[4])
+		if err != nil {
+			return ButtonPayload{}, false
+		}
+		p.Variant = v
+	}
+	return p, true
+}
+
+// ForTenant informa se o botão foi gerado para o tenant (payload sem tenant
+// vale para qualquer um).
+func (p ButtonPayload) ForTenant(cnpj string) bool {
+	return p.Tenant == "" || p.Tenant == cnpj
+}
+
+// isButtonReply cobre os tipos de resposta interativa dos gateways.
+func isButtonReply(msgType string) bool {
+	switch msgType {
+	case "templatebuttonreplymessage", "buttonsresponsemessage", "listresponsemessage",
+		"interactiveresponsemessage", "buttonreply", "listreply":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package flow
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"pac-lead-agent/internal/money"
+	"pac-lead-agent/internal/pix"
+	"pac-lead-agent/internal/qrcode"
+)
+
+func init() {
+	registerTool(Tool{
+		Name: "pix_payment",
+		Description: "Gera e envia ao cliente o QR Code e o código Pix copia-e-cola do último pedido confirmado " +
+			"(ou do carrinho atual, se ainda não houver pedido).",
+		Parameters: objectSchema(map[string]any{}),
+		Handler:    toolPixPayment,
+	})
+}
+
+var errPixNotConfigured = errors.New("pix não configurado para esta loja")
+
+// pixPayload monta a cobrança Pix a partir das settings do tenant
+// (pix_key, pix_merchant_name, pix_city, pix_url opcional para cobrança dinâmica).
+func pixPayload(s Settings, amount money.Amount, txid string) (pix.Payload, error) {
+	p := pix.Payload{
+		Key:          s.String("pix_key"),
+		MerchantName: s.String("pix_merchant_name", "company_name", "razao_social"),
+		MerchantCity: s.String("pix_city", "city", "cidade"),
+		URL:          s.String("pix_url"),
+		Amount:       amount,
+		TxID:         txid,
+	}
+	if p.Key == "" && p.URL == "" {
+		return p, errPixNotConfigured
+	}
+	return p, nil
+}
+
+// SendPixCharge envia o QR Code (imagem) e o copia-e-cola (texto) da cobrança.
+func SendPixCharge(ctx context.Context, s *Session, amount money.Amount, txid string) (string, error) {
+	p, err := pixPayload(s.Tenant.Settings, amount, txid)
+	if err != nil {
+		return "", err
+	}
+	code, err := p.BRCode()
+	if err != nil {
+		return "", err
+	}
+	qr, err := qrcode.Encode(code)
+	if err != nil {
+		return "", err
+	}
+	png, err := qr.PNG(8)
+	if err != nil {
+		return "", err
+	}
+	caption := "Pix de " + amount.String() + " — escaneie o QR Code ou use o código copia e cola abaixo."
+	if err := s.Whats.SendImageBase64(ctx, s.Number, base64.StdEncoding.EncodeToString(png), caption); err != nil {
+		return "", err
+	}
+	// O copia-e-cola vai sozinho na mensagem para facilitar copiar no celular
+	return code, s.Whats.SendText(ctx, s.Number, code)
+}
+
+func toolPixPayment(ctx context.Context, s *Session, _ json.RawMessage) (string, error) {
+	conv := s.LoadConversation(ctx)
+	amount, txid := conv.Cart.Total(), ""
+	if conv.LastOrder != nil {
+		amount = conv.LastOrder.Total
+		txid = strings.TrimPrefix(conv.LastOrder.ID, "PED-")
+	}
+	if amount <= 0 {
+		return "", errors.New("nenhum pedido ou carrinho com valor para cobrar")
+	}
+	code, err := SendPixCharge(ctx, s, amount, txid)
+	if err != nil {
+		return "", err
+	}
+	b, _ := json.Marshal(map[string]any{
+		"ok":      true,
+		"amount":  amount.String(),
+		"brcode":  code,
+		"message": "QR Code e copia-e-cola já enviados ao cliente. Peça para avisar quando pagar.",
+	})
+	return string(b), nil
+}
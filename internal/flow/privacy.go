@@ -0,0 +1,205 @@
+package flow
+
+import (
+	"context"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/leads"
+	"pac-lead-agent/internal/optout"
+	"pac-lead-agent/internal/phone"
+	"pac-lead-agent/internal/qualify"
+	"pac-lead-agent/internal/scheduler"
+	"pac-lead-agent/internal/state"
+	"pac-lead-agent/internal/transcript"
+)
+
+// handleOptOut trata "SAIR"/"PARE" (registra e confirma) e "VOLTAR" (remove o
+// registro). Números com opt-out não recebem resposta do bot. Devolve true se
+// a mensagem foi tratada aqui.
+func handleOptOut(ctx context.Context, s *Session, text string) (bool, error) {
+	reg := optout.Default()
+	cnpj := s.Tenant.CNPJ
+	if optout.IsStop(text) {
+		cancelFollowUp(ctx, s)
+		if err := reg.Add(ctx, cnpj, s.Number, optout.Record{Reason: text, Source: "message"}); err != nil {
+			return true, err
+		}
+		return true, s.Whats.SendText(ctx, s.Number,
+			"Pronto! Você não vai mais receber mensagens nossas. Se mudar de ideia, é só enviar *VOLTAR*.")
+	}
+	if !reg.Is(ctx, cnpj, s.Number) {
+		return false, nil
+	}
+	if optout.IsStart(text) {
+		if err := reg.Remove(ctx, cnpj, s.Number); err != nil {
+			return true, err
+		}
+		return true, s.Whats.SendText(ctx, s.Number, "Que bom ter você de volta! 😊 Como posso te ajudar?")
+	}
+	return true, nil
+}
+
+// optedOut informa se o lead da sessão pediu para não receber mensagens.
+func (s *Session) optedOut(ctx context.Context) bool {
+	return optout.Default().Is(ctx, s.Tenant.CNPJ, s.Number)
+}
+
+// Contact identifica um titular de dados: tenant (opções ou CNPJ explícito) e número.
+type Contact struct {
+	Opts   Options
+	CNPJ   string
+	Number string
+}
+
+// resolve devolve o tenant, o número canônico e os clientes, sem criar lead.
+func (c Contact) resolve(ctx context.Context, cfg config.Config) (Tenant, string, *clients.OpenAI, *clients.PacLead) {
+	ai := clients.NewOpenAI(cfg.OpenAIKey, cfg.OpenAIAssistantID)
+	pl := clients.NewPacLead(cfg.PacLeadBaseURL, cfg.PacLeadCRMBaseURL, cfg.PlatformBaseURL)
+	plat := clients.NewPlatform(cfg.PlatformBaseURL)
+	tn := ResolveTenant(cfg, c.Opts, LoadSettings(ctx, plat, pl, c.Opts.OrgID, c.Opts.FlowID))
+	if c.CNPJ != "" {
+		tn.CNPJ = c.CNPJ
+	}
+	return tn, phone.Canonical(c.Number), ai, pl
+}
+
+// findLead procura o lead pelas formas do número (com e sem nono dígito).
+func findLead(ctx context.Context, pl *clients.PacLead, number, cnpj string) (map[string]any, string) {
+	for _, n := range phone.Variants(number) {
+		if out, err := pl.LeadsGeral(ctx, n, cnpj); err == nil && len(out) > 0 {
+			return out, Settings(out).String("Thread_id", "thread_id", "thread", "ThreadID")
+		}
+	}
+	return nil, ""
+}
+
+// SetOptOut registra (ou remove) o opt-out de um número pela API administrativa.
+func SetOptOut(ctx context.Context, cfg config.Config, c Contact, out bool, reason string) error {
+	tn, number, _, _ := c.resolve(ctx, cfg)
+	reg := optout.Default()
+	if !out {
+		return reg.Remove(ctx, tn.CNPJ, number)
+	}
+	_ = scheduler.Default().Cancel(ctx, followUpKind, tn.CNPJ+":"+number)
+	return reg.Add(ctx, tn.CNPJ, number, optout.Record{Reason: reason, Source: "admin"})
+}
+
+// GetOptOut consulta o opt-out de um número.
+func GetOptOut(ctx context.Context, cfg config.Config, c Contact) (optout.Record, bool) {
+	tn, number, _, _ := c.resolve(ctx, cfg)
+	return optout.Default().Get(ctx, tn.CNPJ, number)
+}
+
+// ExportContact reúne tudo o que o agente guarda sobre o número (acesso do
+// titular, LGPD art. 18): lead, mensagens da thread, estado da conversa,
+// perfil de qualificação, contadores, opt-out, buffer e transcrição local.
+func ExportContact(ctx context.Context, cfg config.Config, c Contact) (map[string]any, error) {
+	tn, number, ai, pl := c.resolve(ctx, cfg)
+	st := state.Default()
+	out := map[string]any{
+		"numero":       number,
+		"cnpj_empresa": tn.CNPJ,
+		"exportado_em": time.Now().UTC(),
+	}
+	lead, threadID := findLead(ctx, pl, number, tn.CNPJ)
+	out["lead"] = lead
+	if threadID != "" {
+		out["thread_id"] = threadID
+		msgs, err := ai.ThreadMessages(ctx, threadID)
+		if err != nil {
+			return nil, err
+		}
+		out["mensagens"] = msgs
+	}
+	var conv Conversation
+	if ok, _ := st.Get(ctx, conversationKey(tn, number), &conv); ok {
+		out["conversa"] = conv
+	}
+	var prof qualify.Profile
+	if ok, _ := st.Get(ctx, profileKey(tn, number), &prof); ok {
+		out["perfil"] = prof
+	}
+	in, sent := leads.Default().Stats(ctx, tn.CNPJ, number)
+	out["mensagens_recebidas"], out["mensagens_enviadas"] = in, sent
+	if rec, ok := optout.Default().Get(ctx, tn.CNPJ, number); ok {
+		out["opt_out"] = rec
+	}
+	if buf, err := clients.NewRedisFromEnv().GetAllBuffer(ctx, number); err == nil && len(buf) > 0 {
+		out["buffer"] = buf
+	}
+	var msgs []transcript.Message
+	err := transcript.Each(ctx, transcript.Default(), transcript.Query{Tenant: tn.CNPJ, Number: number}, func(m transcript.Message) error {
+		msgs = append(msgs, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	out["transcricao"] = msgs
+	return out, nil
+}
+
+// leadPartial é o resultado da etapa "lead" quando só foi possível anonimizar.
+const leadPartial = "parcial: nome e thread apagados; número e CNPJ permanecem no PacLead (sem endpoint de exclusão)"
+
+// EraseContact apaga os dados do número (eliminação, LGPD art. 18): thread na
+// OpenAI, estado, perfil, contadores, buffer, transcrição e agendamentos; o
+// lead no PacLead só pode ser anonimizado (etapa "lead" volta como parcial).
+// O registro de opt-out é mantido para continuar respeitando o pedido.
+// Devolve o resultado de cada etapa ("ok", parcial ou o erro).
+func EraseContact(ctx context.Context, cfg config.Config, c Contact) map[string]string {
+	tn, number, ai, pl := c.resolve(ctx, cfg)
+	st := state.Default()
+	release := lockConversation(ctx, st, c.Opts.OrgID+":"+number)
+	defer release()
+
+	res := map[string]string{}
+	step := func(name string, err error) {
+		if err != nil {
+			res[name] = err.Error()
+			return
+		}
+		res[name] = "ok"
+	}
+	lead, threadID := findLead(ctx, pl, number, tn.CNPJ)
+	if threadID != "" {
+		step("thread", ai.DeleteThread(ctx, threadID))
+	}
+	if lead != nil {
+		rec := leadFromMap(lead)
+		rec.CNPJCPF = tn.CNPJ
+		if rec.Numero == "" {
+			rec.Numero = number
+		}
+		// descarta a gravação pendente antes, para não recriar o lead
+		leads.Default().Forget(ctx, tn.CNPJ, number)
+		if err := pl.AnonymizeLead(ctx, rec); err != nil {
+			step("lead", err)
+		} else {
+			// não é eliminação completa: o PacLead não tem endpoint de exclusão
+			res["lead"] = leadPartial
+		}
+	}
+	step("conversa", st.Delete(ctx, conversationKey(tn, number)))
+	step("perfil", st.Delete(ctx, profileKey(tn, number)))
+	leads.Default().Forget(ctx, tn.CNPJ, number)
+	res["contadores"] = "ok"
+	step("buffer", clients.NewRedisFromEnv().ClearBuffer(ctx, number))
+	_, err := transcript.Default().Delete(ctx, tn.CNPJ, number)
+	step("transcricao", err)
+	sch := scheduler.Default()
+	id := tn.CNPJ + ":" + number
+	step("agendamentos", firstErr(sch.Cancel(ctx, followUpKind, id), sch.Cancel(ctx, flowWaitKind, id)))
+	return res
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,44 +2,136 @@ package flow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"pac-lead-agent/internal/catalog"
 	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/crm"
+	"pac-lead-agent/internal/money"
 )
 
-func SendProductsCarousel(ctx context.Context, pl *clients.PacLead, whats *clients.Whats, cnpj, number string, ids []string) error {
+// moreButtonID identifica o botão "Ver mais" enviado após um carrossel paginado.
+const moreButtonID = "ver_mais"
+
+var errProductNotFound = errors.New("produto não encontrado no catálogo")
+
+// fetchProduct busca um produto pelo ID no catálogo do tenant.
+func fetchProduct(ctx context.Context, s *Session, id string) (catalog.Product, error) {
+	idCopy := id
+	prods, err := s.PL.Produtos(ctx, s.Tenant.CNPJ, &idCopy)
+	if err != nil {
+		return catalog.Product{}, err
+	}
+	if len(prods) == 0 {
+		return catalog.Product{}, errProductNotFound
+	}
+	p := catalog.Parse(prods[0])
+	if p.ID == "" {
+		p.ID = id
+	}
+	return p, nil
+}
+
+// SendProductsCarousel envia a primeira página de produtos disponíveis (até o
+// limite do provedor) e guarda os IDs restantes na conversa para o botão "Ver mais".
+// Produtos inativos ou sem estoque são omitidos.
+func SendProductsCarousel(ctx context.Context, s *Session, ids []string) error {
 	if len(ids) == 0 {
 		return nil
 	}
-	cards := make([]map[string]any, 0, len(ids))
-	for i := 0; i < len(ids) && i < 5; i++ {
-		id := ids[i]
-		idCopy := id
-		prods, err := pl.Produtos(ctx, cnpj, &idCopy)
-		if err != nil || len(prods) == 0 {
+	limit := s.carouselLimit()
+	cards := make([]map[string]any, 0, limit)
+	shown := make([]string, 0, limit) // IDs que viraram card (pulados não contam)
+	next := 0
+	for ; next < len(ids) && len(cards) < limit; next++ {
+		id := ids[next]
+		p, err := fetchProduct(ctx, s, id)
+		if err != nil || !p.Available() {
 			continue
 		}
-		p := prods[0]
-		nome, _ := p["nome"].(string)
-		desc, _ := p["descricao"].(string)
-		preco := fmt.Sprintf("%v", p["preco"])
-		// Texto com descrição e preço formatado
-		text := strings.TrimSpace(desc) + "\nPreço: R$ " + preco
-		// Usa a base do cliente para compor a URL da imagem
-		image := fmt.Sprintf("%s/produtos/imagem?id=%s&id_empresa=%d", pl.Base, id, 1)
+		// Texto com descrição e preço formatado (R$ 1.299,90)
+		text := p.Description + "\n" + money.PriceLine(p.Price, p.Original, p.Installments)
 		cards = append(cards, map[string]any{
 			"text":  text,
-			"image": image,
+			"image": productImageURL(s.Cfg, s.PL, s.Tenant, id, p.CompanyID),
 			"buttons": []map[string]any{{
-				"id":   fmt.Sprintf("Vou querer o %s", nome),
-				"text": fmt.Sprintf("Vou querer o %s", nome),
+				"id":   ButtonPayload{Action: ActionBuy, Tenant: s.Tenant.CNPJ, ProductID: id}.Encode(),
+				"text": fmt.Sprintf("Vou querer o %s", p.Name),
 				"type": "REPLY",
 			}},
 		})
+		shown = append(shown, id)
 	}
+
+	conv := s.LoadConversation(ctx)
+	conv.PendingProducts = append([]string(nil), ids[next:]...)
+	_ = s.SaveConversation(ctx, conv)
+
 	if len(cards) == 0 {
 		return nil
 	}
-	return whats.SendCarousel(ctx, number, "Encante-se com os destaques!", cards)
+	if err := s.Whats.SendCarousel(ctx, s.Number, "Encante-se com os destaques!", cards); err != nil {
+		return err
+	}
+	emitCRM(s, crm.EventProductsShown, strings.Join(shown, ","), map[string]any{"product_ids": shown})
+	if len(conv.PendingProducts) > 0 {
+		return s.Whats.SendButtons(ctx, s.Number,
+			fmt.Sprintf("Tenho mais %d opções para você.", len(conv.PendingProducts)),
+			[]string{"Ver mais|" + moreButtonID})
+	}
+	return nil
+}
+
+// SendNextProductsPage envia a próxima página guardada; retorna false se não houver.
+func SendNextProductsPage(ctx context.Context, s *Session) (bool, error) {
+	conv := s.LoadConversation(ctx)
+	if len(conv.PendingProducts) == 0 {
+		return false, nil
+	}
+	return true, SendProductsCarousel(ctx, s, conv.PendingProducts)
+}
+
+// SendVariantOptions pede ao lead o tamanho/cor: botões até 3 opções, lista acima disso.
+func SendVariantOptions(ctx context.Context, s *Session, p catalog.Product) error {
+	variants := p.AvailableVariants()
+	choices := make([]string, 0, len(variants)+1)
+	text := fmt.Sprintf("Qual opção de *%s* você prefere?", p.Name)
+	if len(variants) > 3 {
+		choices = append(choices, "[Opções disponíveis]")
+	}
+	for _, v := range variants {
+		id := ButtonPayload{Action: ActionVariant, Tenant: s.Tenant.CNPJ, ProductID: p.ID, Variant: v.SKU}.Encode()
+		choice := v.Label() + "|" + id
+		if len(variants) > 3 {
+			choice += "|" + p.PriceFor(v).String()
+		}
+		choices = append(choices, choice)
+	}
+	if len(variants) > 3 {
+		return s.Whats.SendList(ctx, s.Number, text, "Escolher", choices)
+	}
+	return s.Whats.SendButtons(ctx, s.Number, text, choices)
+}
+
+// isMoreRequest reconhece o toque no botão "Ver mais" (id ou texto).
+func isMoreRequest(text string) bool {
+	switch strings.ToLower(strings.TrimSpace(text)) {
+	case moreButtonID, "ver mais", "ver mais produtos", "mais opções", "mais opcoes":
+		return true
+	}
+	return false
+}
+
+// productImageURL prefere o proxy público (/media/products/<empresa>/<id>), que
+// entrega HTTPS e imagens dentro dos limites do WhatsApp. Sem PUBLIC_BASE_URL,
+// cai na URL direta do :8889.
+func productImageURL(cfg config.Config, pl *clients.PacLead, tn Tenant, id, productCompany string) string {
+	company := tn.companyFor(cfg, productCompany)
+	if tn.MediaBase != "" {
+		return fmt.Sprintf("%s/media/products/%s/%s.jpg", tn.MediaBase, company, id)
+	}
+	return pl.ProdutoImagemURL(id, company)
 }
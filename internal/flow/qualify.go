@@ -0,0 +1,124 @@
+package flow
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/crm"
+	"pac-lead-agent/internal/document"
+	"pac-lead-agent/internal/qualify"
+	"pac-lead-agent/internal/state"
+)
+
+// qualifyWindow é quantas mensagens recentes entram na extração.
+const qualifyWindow = 20
+
+// profileKey fica fora da Conversation: a extração roda em paralelo ao próximo
+// turno e não pode sobrescrever o carrinho.
+func profileKey(tn Tenant, number string) string {
+	return "profile:" + tn.CNPJ + ":" + number
+}
+
+// LoadProfile devolve o perfil de qualificação do lead.
+func (s *Session) LoadProfile(ctx context.Context) qualify.Profile {
+	var p qualify.Profile
+	if s.Store != nil {
+		_, _ = s.Store.Get(ctx, profileKey(s.Tenant, s.Number), &p)
+	}
+	return p
+}
+
+// qualificationEnabled: ligado por padrão; o tenant desliga com qualification_enabled=false.
+func qualificationEnabled(st Settings) bool {
+	if _, ok := st["qualification_enabled"]; !ok {
+		return true
+	}
+	return st.Bool("qualification_enabled")
+}
+
+// qualifyAsync roda a extração depois do turno sem atrasar a resposta ao lead.
+// A goroutine recebe uma cópia dos valores que usa (a Session pertence ao turno,
+// que segue e termina sem esperar por ela).
+func qualifyAsync(s *Session) {
+	if !qualificationEnabled(s.Tenant.Settings) || s.Cfg.ExtractionModel == "" {
+		return
+	}
+	t := profileTask{
+		AI: s.AI, PL: s.PL, Store: s.Store, Model: s.Cfg.ExtractionModel,
+		Tenant: s.Tenant, OrgID: s.Opts.OrgID, Number: s.Number, Name: s.LeadName, ThreadID: s.ThreadID,
+	}
+	go func() {
+		if err := t.run(); err != nil {
+			log.Println("qualify:", err, "numero:", t.Number)
+		}
+	}()
+}
+
+// profileTTL expira o perfil de leads inativos; cada atualização renova o prazo.
+const profileTTL = 90 * 24 * time.Hour
+
+// profileTask é o que a extração precisa de uma sessão.
+type profileTask struct {
+	AI       *clients.OpenAI
+	PL       *clients.PacLead
+	Store    state.Store
+	Model    string
+	Tenant   Tenant
+	OrgID    string
+	Number   string
+	Name     string
+	ThreadID string
+}
+
+// run extrai o perfil da thread e, sob o lock da conversa, funde com o perfil
+// gravado (extrações de turnos seguidos não se sobrescrevem). A chamada ao
+// modelo fica fora do lock para não segurar o próximo turno.
+func (t profileTask) run() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 45*time.Second)
+	defer cancel()
+	ext := qualify.Extractor{AI: t.AI, Model: t.Model}
+	next, err := ext.Extract(ctx, t.ThreadID, qualifyWindow)
+	if err != nil {
+		return err
+	}
+	// CPF/CNPJ só entra no perfil com dígitos verificadores válidos
+	if next.Document.Known() {
+		if _, ok := document.Validate(next.Document.Value); ok {
+			next.Document.Value = document.Normalize(next.Document.Value)
+		} else {
+			next.Document = qualify.Field{}
+		}
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), lockWait+15*time.Second)
+	defer cancel()
+	release := lockConversation(ctx, t.Store, t.OrgID+":"+t.Number)
+	defer release()
+
+	var prof qualify.Profile
+	key := profileKey(t.Tenant, t.Number)
+	if _, err := t.Store.Get(ctx, key, &prof); err != nil {
+		return err
+	}
+	prevTag := prof.Tag
+	if !prof.Merge(next, time.Now()) {
+		return nil
+	}
+	if err := t.Store.Set(ctx, key, prof, profileTTL); err != nil {
+		return err
+	}
+	t.emit(crm.EventProfileUpdated, prof.At.Format(time.RFC3339Nano), prof.Values())
+	if prof.Tag != "cold" && (prevTag == "" || prevTag == "cold") {
+		t.emit(crm.EventQualified, "", prof.Values())
+	}
+	return nil
+}
+
+// emit é o emitCRM sem Session.
+func (t profileTask) emit(typ crm.EventType, ref string, data map[string]any) {
+	crm.Default().Emit(t.PL, crmMapping(t.Tenant.Settings), crm.Event{
+		Type: typ, CNPJ: t.Tenant.CNPJ, OrgID: t.Tenant.OrgID, Number: t.Number, Name: t.Name, Ref: ref, Data: data,
+	})
+}
@@ -2,19 +2,38 @@ package flow
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/leads"
+	"pac-lead-agent/internal/phone"
 	"pac-lead-agent/internal/types"
 )
 
 func EnsureThread(ctx context.Context, ai *clients.OpenAI, pl *clients.PacLead, number, cnpj string) (string, error) {
-	// Tenta recuperar lead existente e reaproveitar Thread_id
-	if out, err := pl.LeadsGeral(ctx, number, cnpj); err == nil && out != nil {
+	lead, _, err := EnsureLead(ctx, ai, pl, number, cnpj, "")
+	return lead.ThreadID, err
+}
+
+// EnsureLead recupera o lead existente (reaproveitando o Thread_id) ou cria
+// thread e lead novos. Devolve o registro usado no acompanhamento do lead e se
+// ele foi criado agora.
+func EnsureLead(ctx context.Context, ai *clients.OpenAI, pl *clients.PacLead, number, cnpj, name string) (types.LeadRecord, bool, error) {
+	// Tenta recuperar lead existente e reaproveitar Thread_id. Procura também a
+	// forma antiga do número (sem nono dígito); a próxima gravação migra o lead
+	// para a chave canônica.
+	for _, n := range phone.Variants(number) {
+		out, err := pl.LeadsGeral(ctx, n, cnpj)
+		if err != nil || out == nil {
+			continue
+		}
 		for _, k := range []string{"Thread_id", "thread_id", "thread", "ThreadID"} {
 			if v, ok := out[k]; ok {
 				if s, ok := v.(string); ok && s != "" {
-					return s, nil
+					lead := leadFromMap(out)
+					lead.ThreadID, lead.Numero, lead.CNPJCPF = s, number, cnpj
+					return lead, false, nil
 				}
 			}
 		}
@@ -22,20 +41,40 @@ func EnsureThread(ctx context.Context, ai *clients.OpenAI, pl *clients.PacLead,
 	// Cria nova thread e salva no lead
 	tid, err := ai.CreateThread(ctx)
 	if err != nil {
-		return "", err
+		return types.LeadRecord{}, false, err
 	}
-	_, _ = pl.LeadPost(ctx, types.LeadRecord{
+	lead := types.LeadRecord{
 		ID:         0,
-		Nome:       "",
+		Nome:       strings.TrimSpace(name),
 		Numero:     number,
 		Status:     1,
 		Lead:       1,
 		ThreadID:   tid,
-		DataUltMsg: time.Now().Format("2006-01-02 15:04"),
+		DataUltMsg: time.Now().In(leads.Location).Format("2006-01-02 15:04"),
 		UltMsgNum:  "",
 		CNPJCPF:    cnpj,
-	})
-	return tid, nil
+	}
+	if out, err := pl.LeadPost(ctx, lead); err == nil && out != nil {
+		if id := leadFromMap(out).ID; id != 0 {
+			lead.ID = id
+		}
+	}
+	return lead, true, nil
+}
+
+// leadFromMap converte a resposta do :8889 (campos com tipos variados) em LeadRecord.
+func leadFromMap(m map[string]any) types.LeadRecord {
+	s := Settings(m)
+	return types.LeadRecord{
+		ID:         s.Int(0, "id"),
+		Nome:       s.String("nome"),
+		Numero:     s.String("numero"),
+		Status:     s.Int(1, "status"),
+		Lead:       s.Int(1, "lead"),
+		DataUltMsg: s.String("data_ult_msg"),
+		UltMsgNum:  s.String("ult_msg_numero"),
+		CNPJCPF:    s.String("cnpj_cpf"),
+	}
 }
 
 // Mantém a função original (compatibilidade)
@@ -0,0 +1,100 @@
+package flow
+
+import (
+	"context"
+	"strings"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/leads"
+	"pac-lead-agent/internal/state"
+	"pac-lead-agent/internal/types"
+)
+
+// Session agrupa clientes e identificadores da conversa em andamento, evitando
+// repassar meia dúzia de parâmetros entre os handlers do fluxo.
+type Session struct {
+	Cfg      config.Config
+	Whats    *clients.Whats
+	AI       *clients.OpenAI
+	PL       *clients.PacLead
+	Store    state.Store
+	Tenant   Tenant
+	Number   string
+	LeadName string
+	ThreadID string
+	// Prompt é o prompt final do tenant, enviado como instructions do run.
+	Prompt string
+	// Opts são as opções de origem (instância/tenant), reaproveitadas por
+	// follow-ups e campanhas que abrem a sessão fora de um webhook.
+	Opts Options
+	// RunID é o último run do assistente, gravado na transcrição das respostas.
+	RunID string
+	// InboundAudio indica que a mensagem sendo respondida veio em áudio
+	// (política "mirror" de resposta por voz).
+	InboundAudio bool
+}
+
+// NewSession resolve tenant, prompt, lead e thread de um contato e devolve a
+// sessão pronta para responder. Também liga o acompanhamento de mensagens
+// enviadas (registro do lead e transcrição). O chamador deve segurar o lock
+// da conversa.
+func NewSession(ctx context.Context, cfg config.Config, o Options, number, name string) (*Session, types.LeadRecord, bool, error) {
+	// escolhe o token da instância se vier do header; caso contrário, usa o global do cfg
+	token := cfg.UAzapiToken
+	if strings.TrimSpace(o.InstanceToken) != "" {
+		token = o.InstanceToken
+	}
+
+	whats := clients.NewWhats(cfg.UAzapiBaseURL, token)
+	ai := clients.NewOpenAI(cfg.OpenAIKey, cfg.OpenAIAssistantID)
+	pl := clients.NewPacLead(cfg.PacLeadBaseURL, cfg.PacLeadCRMBaseURL, cfg.PlatformBaseURL)
+	// (ADICIONADO) cliente dedicado da Plataforma para buscar configurações do agente
+	plat := clients.NewPlatform(cfg.PlatformBaseURL)
+
+	// Resolve tenant (CNPJ, id_empresa, mídia) via settings; fallback mantém constante
+	settings := LoadSettings(ctx, plat, pl, o.OrgID, o.FlowID)
+	tn := ResolveTenant(cfg, o, settings)
+
+	// Obtém prompt final (DEFAULT + customizações do cliente)
+	prompt, _ := BuildPrompt(ctx, cfg, pl, o.OrgID, o.FlowID)
+
+	lead, created, err := EnsureLead(ctx, ai, pl, number, tn.CNPJ, name)
+	if err != nil {
+		return nil, lead, false, err
+	}
+	tracker := leads.Default()
+	var sess *Session
+	whats.OnSent = func(ctx context.Context, path string, body map[string]any, messageID string) {
+		tracker.Record(ctx, pl, leads.Event{Lead: lead, Preview: outboundPreview(path, body)})
+		sess.recordOutbound(ctx, path, body, messageID, nil)
+	}
+	whats.OnFailed = func(ctx context.Context, path string, body map[string]any, err error) {
+		sess.recordOutbound(ctx, path, body, "", err)
+	}
+	sess = &Session{
+		Cfg:      cfg,
+		Whats:    whats,
+		AI:       ai,
+		PL:       pl,
+		Store:    state.Default(),
+		Tenant:   tn,
+		Number:   number,
+		LeadName: strings.TrimSpace(name),
+		ThreadID: lead.ThreadID,
+		Prompt:   prompt,
+		Opts:     o,
+	}
+	if sess.LeadName == "" {
+		sess.LeadName = lead.Nome
+	}
+	return sess, lead, created, nil
+}
+
+// carouselLimit respeita WHATS_CAROUSEL_LIMIT e, na ausência, o limite do provedor.
+func (s *Session) carouselLimit() int {
+	if s.Cfg.CarouselLimit > 0 {
+		return s.Cfg.CarouselLimit
+	}
+	return clients.CarouselLimit(s.Cfg.WhatsProvider)
+}
@@ -0,0 +1,98 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"pac-lead-agent/internal/clients"
+)
+
+// Settings encapsula o JSON de /api/agent/settings com getters tolerantes a tipo
+// (a plataforma às vezes devolve números como string e vice-versa).
+type Settings map[string]any
+
+// LoadSettings busca as configurações do tenant na Plataforma e, em caso de falha,
+// no cliente PacLead (compatibilidade). Nunca retorna nil.
+func LoadSettings(ctx context.Context, plat *clients.Platform, pl *clients.PacLead, orgID, flowID string) Settings {
+	if plat != nil {
+		if s, err := plat.GetAgentSettings(ctx, orgID, flowID); err == nil && s != nil {
+			return Settings(s)
+		}
+	}
+	if pl != nil {
+		if s, err := pl.GetAgentSettings(ctx, orgID, flowID); err == nil && s != nil {
+			return Settings(s)
+		}
+	}
+	return Settings{}
+}
+
+// String retorna o primeiro valor não vazio entre as chaves informadas.
+func (s Settings) String(keys ...string) string {
+	for _, k := range keys {
+		switch v := s[k].(type) {
+		case string:
+			if t := strings.TrimSpace(v); t != "" {
+				return t
+			}
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		case json.Number:
+			return v.String()
+		}
+	}
+	return ""
+}
+
+// Int retorna o primeiro valor numérico entre as chaves; def se nenhum existir.
+func (s Settings) Int(def int, keys ...string) int {
+	for _, k := range keys {
+		switch v := s[k].(type) {
+		case float64:
+			return int(v)
+		case string:
+			if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+				return n
+			}
+		}
+	}
+	return def
+}
+
+// Bool aceita true/false, "true"/"1"/"sim" e números.
+func (s Settings) Bool(keys ...string) bool {
+	for _, k := range keys {
+		switch v := s[k].(type) {
+		case bool:
+			return v
+		case float64:
+			return v != 0
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "1", "sim", "yes", "on":
+				return true
+			case "":
+				continue
+			default:
+				return false
+			}
+		}
+	}
+	return false
+}
+
+// Decode converte o valor de uma chave (objeto/lista) para a struct informada.
+// Retorna false se a chave não existir ou não for compatível.
+func (s Settings) Decode(key string, out any) bool {
+	v, ok := s[key]
+	if !ok || v == nil {
+		return false
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, out) == nil
+}
@@ -0,0 +1,96 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pac-lead-agent/internal/cart"
+	"pac-lead-agent/internal/shipping"
+)
+
+func init() {
+	registerTool(Tool{
+		Name:        "shipping_quote",
+		Description: "Calcula as opções de frete (preço e prazo) para o CEP do cliente, considerando os itens do carrinho.",
+		Parameters: objectSchema(map[string]any{
+			"cep": map[string]any{"type": "string", "description": "CEP de entrega (8 dígitos, com ou sem hífen)"},
+		}, "cep"),
+		Handler: toolShippingQuote,
+	})
+	registerTool(Tool{
+		Name:        "shipping_choose",
+		Description: "Registra no pedido a opção de frete escolhida pelo cliente (nome retornado por shipping_quote).",
+		Parameters: objectSchema(map[string]any{
+			"option": map[string]any{"type": "string"},
+		}, "option"),
+		Handler: toolShippingChoose,
+	})
+}
+
+// shippingTable lê a tabela de frete do tenant (settings "shipping").
+func shippingTable(s Settings) (shipping.Table, bool) {
+	var t shipping.Table
+	if !s.Decode("shipping", &t) || len(t.Rules) == 0 {
+		return t, false
+	}
+	return t, true
+}
+
+func toolShippingQuote(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		CEP string `json:"cep"`
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	table, ok := shippingTable(s.Tenant.Settings)
+	if !ok {
+		return "", errors.New("frete não configurado para esta loja; combine a entrega com o cliente")
+	}
+	conv := s.LoadConversation(ctx)
+	opts, err := table.Quote(a.CEP, conv.Cart.WeightKg(), conv.Cart.Subtotal())
+	if err != nil {
+		return "", fmt.Errorf("%w: peça ao cliente o CEP com 8 dígitos", err)
+	}
+	if len(opts) == 0 {
+		return `{"options":[],"message":"Não entregamos nesse CEP pelas modalidades cadastradas."}`, nil
+	}
+	conv.ShippingQuote = opts
+	if err := s.SaveConversation(ctx, conv); err != nil {
+		return "", err
+	}
+	out := make([]map[string]any, 0, len(opts))
+	for _, o := range opts {
+		price := o.Price.String()
+		if o.Free {
+			price = "grátis"
+		}
+		out = append(out, map[string]any{"option": o.Name, "price": price, "delivery": o.Delivery()})
+	}
+	b, _ := json.Marshal(map[string]any{"cep": shipping.FormatCEP(opts[0].CEP), "options": out})
+	return string(b), nil
+}
+
+func toolShippingChoose(ctx context.Context, s *Session, raw json.RawMessage) (string, error) {
+	var a struct {
+		Option string `json:"option"`
+	}
+	if err := json.Unmarshal(raw, &a); err != nil {
+		return "", err
+	}
+	conv := s.LoadConversation(ctx)
+	for _, o := range conv.ShippingQuote {
+		if !strings.EqualFold(o.Name, strings.TrimSpace(a.Option)) {
+			continue
+		}
+		conv.Cart.Shipping = &cart.Shipping{Name: o.Name, CEP: o.CEP, Price: o.Price, Delivery: o.Delivery()}
+		if err := s.SaveConversation(ctx, conv); err != nil {
+			return "", err
+		}
+		return cartResult(conv.Cart, "Frete registrado: "+o.Name), nil
+	}
+	return "", fmt.Errorf("opção %q não está na última cotação; chame shipping_quote novamente", a.Option)
+}
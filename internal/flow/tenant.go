@@ -0,0 +1,61 @@
+package flow
+
+import (
+	"log"
+	"strings"
+
+	"pac-lead-agent/internal/config"
+	"pac-lead-agent/internal/document"
+)
+
+// defaultCNPJ mantém o comportamento histórico quando o tenant não tem tax_id.
+const defaultCNPJ = "23820015000100"
+
+// Tenant reúne os identificadores do cliente resolvidos para a mensagem atual.
+type Tenant struct {
+	OrgID      string
+	FlowID     string
+	InstanceID string
+	CNPJ       string
+	// CompanyID é o id_empresa usado pelo PacLead (:8889) para imagens e catálogo.
+	CompanyID string
+	// MediaBase é a URL pública (HTTPS) deste serviço, usada no proxy de mídia.
+	MediaBase string
+	Settings  Settings
+}
+
+// ResolveTenant combina opções do webhook, settings da plataforma e config global.
+func ResolveTenant(cfg config.Config, o Options, s Settings) Tenant {
+	t := Tenant{
+		OrgID:      o.OrgID,
+		FlowID:     o.FlowID,
+		InstanceID: o.InstanceID,
+		CNPJ:       defaultCNPJ,
+		CompanyID:  s.String("company_id", "id_empresa", "empresa_id"),
+		MediaBase:  strings.TrimRight(cfg.PublicBaseURL, "/"),
+		Settings:   s,
+	}
+	if s == nil {
+		t.Settings = Settings{}
+	}
+	if v := s.String("tax_id"); v != "" {
+		// CNPJ alfanumérico: não basta manter só dígitos
+		t.CNPJ = document.Normalize(v)
+		if _, ok := document.Validate(t.CNPJ); !ok {
+			log.Printf("tenant org=%s: tax_id %q inválido (dígitos verificadores não conferem); o catálogo pode não ser encontrado", o.OrgID, v)
+		}
+	}
+	return t
+}
+
+// companyFor resolve o id_empresa: settings do tenant, depois o próprio registro
+// do catálogo e, por último, o default configurado.
+func (t Tenant) companyFor(cfg config.Config, productCompany string) string {
+	if t.CompanyID != "" {
+		return t.CompanyID
+	}
+	if productCompany != "" {
+		return productCompany
+	}
+	return cfg.PacLeadCompanyID
+}
@@ -0,0 +1,148 @@
+package flow
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+)
+
+// Tool é uma function exposta ao assistente. Cada subsistema (carrinho, Pix,
+// frete, ...) registra as suas em init().
+type Tool struct {
+	Name        string
+	Description string
+	// Parameters é o JSON Schema dos argumentos.
+	Parameters map[string]any
+	Handler    func(ctx context.Context, s *Session, args json.RawMessage) (string, error)
+}
+
+var toolRegistry = map[string]Tool{}
+
+func registerTool(t Tool) {
+	toolRegistry[t.Name] = t
+}
+
+// toolDefinitions devolve as tools no formato do Assistants API, em ordem estável.
+func toolDefinitions() []map[string]any {
+	names := make([]string, 0, len(toolRegistry))
+	for n := range toolRegistry {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	out := make([]map[string]any, 0, len(names))
+	for _, n := range names {
+		t := toolRegistry[n]
+		out = append(out, map[string]any{
+			"type": "function",
+			"function": map[string]any{
+				"name":        t.Name,
+				"description": t.Description,
+				"parameters":  t.Parameters,
+			},
+		})
+	}
+	return out
+}
+
+// objectSchema monta um JSON Schema de objeto com as propriedades informadas.
+func objectSchema(props map[string]any, required ...string) map[string]any {
+	if required == nil {
+		required = []string{}
+	}
+	return map[string]any{"type": "object", "properties": props, "required": required}
+}
+
+const (
+	runPollInterval = time.Second
+	runTimeout      = 90 * time.Second
+)
+
+var errRunTimeout = errors.New("assistant run timed out")
+
+// runAssistant envia a mensagem do usuário, cria o run com as tools registradas e
+// acompanha até concluir, executando as functions pedidas pelo assistente.
+func runAssistant(ctx context.Context, s *Session, text string) error {
+	content := []map[string]any{{"type": "text", "text": text}}
+	if err := s.AI.CreateMessage(ctx, s.ThreadID, "user", content); err != nil {
+		return err
+	}
+	runID, err := s.AI.CreateRunWithOptions(ctx, s.ThreadID, clients.RunOptions{
+		Instructions:           s.instructions(),
+		AdditionalInstructions: s.LoadConversation(ctx).Campaign.instructions(time.Now()),
+		Tools:                  toolDefinitions(),
+	})
+	if err != nil {
+		return err
+	}
+	return waitRun(ctx, s, runID)
+}
+
+func waitRun(ctx context.Context, s *Session, runID string) error {
+	s.RunID = runID
+	deadline := time.Now().Add(runTimeout)
+	for time.Now().Before(deadline) {
+		run, err := s.AI.GetRun(ctx, s.ThreadID, runID)
+		if err != nil {
+			return err
+		}
+		switch run.Status {
+		case "completed":
+			return nil
+		case "failed", "cancelled", "expired", "incomplete":
+			return fmt.Errorf("assistant run %s: %s", runID, run.Status)
+		case "requires_action":
+			outputs := make([]clients.ToolOutput, 0, len(run.ToolCalls))
+			for _, tc := range run.ToolCalls {
+				outputs = append(outputs, clients.ToolOutput{ToolCallID: tc.ID, Output: callTool(ctx, s, tc)})
+			}
+			if err := s.AI.SubmitToolOutputs(ctx, s.ThreadID, runID, outputs); err != nil {
+				return err
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			cancelRun(s, runID)
+			return ctx.Err()
+		case <-time.After(runPollInterval):
+		}
+	}
+	cancelRun(s, runID)
+	return errRunTimeout
+}
+
+// cancelRun cancela o run abandonado; sem isso a próxima mensagem do lead
+// falha com "run is active" até o run expirar na OpenAI.
+func cancelRun(s *Session, runID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.AI.CancelRun(ctx, s.ThreadID, runID); err != nil {
+		log.Println("cancel run:", err)
+	}
+}
+
+// callTool executa a tool e devolve a saída como texto; erros viram mensagem
+// para o assistente (o run não pode ficar sem resposta).
+func callTool(ctx context.Context, s *Session, tc clients.ToolCall) string {
+	t, ok := toolRegistry[tc.Name]
+	if !ok {
+		return fmt.Sprintf(`{"error":"ferramenta desconhecida: %s"}`, tc.Name)
+	}
+	args := json.RawMessage(tc.Arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	out, err := t.Handler(ctx, s, args)
+	if err != nil {
+		log.Println("tool error:", tc.Name, err)
+		b, _ := json.Marshal(map[string]string{"error": err.Error()})
+		return string(b)
+	}
+	return out
+}
@@ -0,0 +1,111 @@
+package flow
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/scheduler"
+	"pac-lead-agent/internal/transcript"
+	"pac-lead-agent/internal/types"
+)
+
+// transcriptPurgeKind é o job diário que expurga transcrições fora da retenção.
+const transcriptPurgeKind = "transcriptpurge"
+
+// recordInbound grava a mensagem recebida na transcrição.
+func recordInbound(ctx context.Context, s *Session, in types.Message, msgType, text string) {
+	if text == "" {
+		text = in.ButtonID
+	}
+	s.record(ctx, transcript.Message{
+		Direction: transcript.Inbound,
+		Type:      msgType,
+		Text:      text,
+		MessageID: in.ID,
+		Status:    transcript.StatusReceived,
+	})
+}
+
+// recordOutbound grava um envio ao lead (ligado em Whats.OnSent/OnFailed).
+func (s *Session) recordOutbound(ctx context.Context, path string, body map[string]any, messageID string, err error) {
+	m := transcript.Message{
+		Direction: transcript.Outbound,
+		Type:      outboundType(path, body),
+		Text:      Settings(body).String("text"),
+		MessageID: messageID,
+		RunID:     s.RunID,
+		Status:    transcript.StatusSent,
+	}
+	// arquivos em base64 não vão para a transcrição, só URLs
+	if f := Settings(body).String("file"); strings.HasPrefix(f, "http") {
+		m.Media = f
+	}
+	if err != nil {
+		m.Status, m.Error = transcript.StatusFailed, err.Error()
+	}
+	s.record(ctx, m)
+}
+
+func (s *Session) record(ctx context.Context, m transcript.Message) {
+	m.Tenant, m.OrgID, m.FlowID, m.Number = s.Tenant.CNPJ, s.Opts.OrgID, s.Opts.FlowID, s.Number
+	if err := transcript.Default().Append(ctx, &m); err != nil {
+		log.Println("transcript:", err)
+	}
+}
+
+// outboundType deriva o tipo do envio do path (/send/text) ou, em mídia e
+// menus, do campo "type" do corpo (image, ptt, button, list...).
+func outboundType(path string, body map[string]any) string {
+	kind := strings.TrimPrefix(path, "/send/")
+	if t := Settings(body).String("type"); t != "" && (kind == "media" || kind == "menu") {
+		return t
+	}
+	return kind
+}
+
+// isStatusUpdate reconhece os eventos de recibo de entrega/leitura do gateway.
+func isStatusUpdate(in types.IncomingWebhook) bool {
+	ev := strings.ToLower(in.Event)
+	return in.Body.Message.Status != "" && (strings.Contains(ev, "update") || strings.Contains(ev, "ack"))
+}
+
+// handleStatusUpdate aplica o recibo à mensagem enviada na transcrição.
+func handleStatusUpdate(ctx context.Context, in types.IncomingWebhook) error {
+	status := transcript.NormalizeStatus(in.Body.Message.Status)
+	if status == "" || in.Body.Message.ID == "" {
+		return nil
+	}
+	return transcript.Default().SetStatus(ctx, in.Body.Message.ID, status)
+}
+
+// scheduleTranscriptPurge agenda o expurgo diário se houver retenção
+// configurada e o job ainda não existir (outra réplica pode tê-lo criado).
+func scheduleTranscriptPurge(ctx context.Context, sch *scheduler.Scheduler) {
+	if transcript.Retention() <= 0 {
+		return
+	}
+	if _, ok := sch.Get(ctx, transcriptPurgeKind, "daily"); ok {
+		return
+	}
+	_ = sch.Schedule(ctx, scheduler.Job{Kind: transcriptPurgeKind, ID: "daily", DueAt: time.Now()})
+}
+
+// runTranscriptPurge apaga as mensagens mais antigas que a retenção e
+// reagenda para o dia seguinte.
+func runTranscriptPurge(ctx context.Context, sch *scheduler.Scheduler, j scheduler.Job) error {
+	keep := transcript.Retention()
+	if keep <= 0 {
+		return nil
+	}
+	n, err := transcript.Default().Purge(ctx, time.Now().Add(-keep))
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Printf("transcript: %d mensagens expurgadas", n)
+	}
+	j.DueAt, j.Attempt = time.Now().Add(24*time.Hour), 0
+	return sch.Schedule(ctx, j)
+}
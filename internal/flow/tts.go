@@ -1,15 +1,209 @@
 package flow
 
 import (
-    "context"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
 
-    "pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/ttscache"
+	"pac-lead-agent/internal/whatsfmt"
 )
 
+// Política de resposta em áudio (settings["tts"].audio_reply).
+const (
+	AudioNever  = "never"
+	AudioAlways = "always"
+	// AudioMirror responde em áudio quando o lead mandou áudio.
+	AudioMirror = "mirror"
+)
+
+// Tratamento de respostas longas demais para áudio (settings["tts"].long_reply).
+const (
+	longIntro     = "intro"
+	longSummarize = "summarize"
+)
+
+// introChars limita a abertura falada quando a resposta segue por texto.
+const introChars = 220
+
+// TTSSettings vem de settings["tts"] do tenant.
+type TTSSettings struct {
+	Model    string `json:"model"`
+	Voice    string `json:"voice"`
+	Style    string `json:"style"`    // ex.: "animado e inspirador"
+	Language string `json:"language"` // ex.: "português do Brasil"
+	// Instructions substitui por completo o texto montado com Style/Language.
+	Instructions string `json:"instructions"`
+	AudioReply   string `json:"audio_reply"`
+	// MaxChars é o maior texto falado por inteiro (padrão 600, ~40s de áudio).
+	MaxChars int `json:"max_chars"`
+	// LongReply decide respostas maiores: "intro" (texto + áudio curto de
+	// abertura, padrão) ou "summarize" (áudio com um resumo).
+	LongReply string `json:"long_reply"`
+	// IntroSuffix fecha a abertura falada avisando que o texto segue por
+	// escrito (padrão conforme Language; "-" desliga).
+	IntroSuffix string `json:"intro_suffix"`
+}
+
+func ttsSettings(st Settings) TTSSettings {
+	var t TTSSettings
+	st.Decode("tts", &t)
+	switch strings.ToLower(strings.TrimSpace(t.AudioReply)) {
+	case AudioNever, AudioAlways:
+		t.AudioReply = strings.ToLower(strings.TrimSpace(t.AudioReply))
+	default:
+		t.AudioReply = AudioMirror
+	}
+	if t.MaxChars <= 0 {
+		t.MaxChars = 600
+	}
+	if t.LongReply != longSummarize {
+		t.LongReply = longIntro
+	}
+	switch strings.TrimSpace(t.IntroSuffix) {
+	case "-":
+		t.IntroSuffix = ""
+	case "":
+		t.IntroSuffix = introSuffix(t.Language)
+	}
+	return t
+}
+
+// introSuffix é o aviso padrão para o idioma da voz; idioma sem tradução
+// conhecida fica sem aviso (melhor do que falar em outra língua).
+func introSuffix(language string) string {
+	l := strings.ToLower(language)
+	switch {
+	case l == "" || strings.Contains(l, "portugu"):
+		return "Te mandei os detalhes por escrito aqui embaixo."
+	case strings.Contains(l, "espa") || strings.Contains(l, "spanish"):
+		return "Te envié los detalles por escrito aquí abajo."
+	case strings.Contains(l, "ingl") || strings.Contains(l, "english"):
+		return "I've sent you the details in writing below."
+	}
+	return ""
+}
+
+// speechOptions monta o pedido ao /audio/speech; sempre em Opus (nota de voz).
+func (t TTSSettings) speechOptions() clients.SpeechOptions {
+	o := clients.SpeechOptions{Model: t.Model, Voice: t.Voice, Instructions: t.Instructions, Format: "opus"}
+	if o.Instructions == "" && (t.Style != "" || t.Language != "") {
+		style, lang := t.Style, t.Language
+		if style == "" {
+			style = "natural e simpática"
+		}
+		if lang == "" {
+			lang = "português do Brasil"
+		}
+		o.Instructions = fmt.Sprintf("Fale de forma %s, SEMPRE em %s.", style, lang)
+	}
+	if o.Instructions == "" {
+		o.Instructions = clients.DefaultSpeech.Instructions
+	}
+	return o
+}
+
+// wantsAudio aplica a política do tenant à mensagem recebida.
+func (s *Session) wantsAudio() bool {
+	switch ttsSettings(s.Tenant.Settings).AudioReply {
+	case AudioAlways:
+		return true
+	case AudioMirror:
+		return s.InboundAudio
+	}
+	return false
+}
+
+// sendAudioReply fala o texto (sem marcações de formatação) e envia como nota
+// de voz. Respostas acima de MaxChars viram um resumo falado ou uma abertura
+// curta em áudio seguida do texto completo, conforme o tenant.
+func sendAudioReply(ctx context.Context, s *Session, text string) error {
+	t := ttsSettings(s.Tenant.Settings)
+	spoken := speakable(text)
+	if spoken == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(spoken) <= t.MaxChars {
+		return speak(ctx, s, t, spoken)
+	}
+	if t.LongReply == longSummarize {
+		if sum, err := summarizeForAudio(ctx, s, spoken, t.MaxChars); err == nil && sum != "" {
+			return speak(ctx, s, t, sum)
+		}
+	}
+	intro := whatsfmt.Split(spoken, introChars)[0]
+	if utf8.RuneCountInString(intro) > introChars {
+		intro = string([]rune(intro)[:introChars])
+	}
+	if t.IntroSuffix != "" {
+		intro += " " + t.IntroSuffix
+	}
+	if err := speak(ctx, s, t, intro); err != nil {
+		return err
+	}
+	return sendReply(ctx, s, text)
+}
+
+// speak sintetiza (ou reaproveita do cache) e envia a nota de voz.
+func speak(ctx context.Context, s *Session, t TTSSettings, text string) error {
+	opts := t.speechOptions()
+	key := ttscache.Key(text, opts.Model, opts.Voice, opts.Instructions, opts.Format)
+	cache := ttscache.Default()
+	audio, ok := cache.Get(ctx, key)
+	if !ok {
+		var err error
+		if audio, err = s.AI.Speech(ctx, text, opts); err != nil {
+			return err
+		}
+		cache.Set(ctx, key, audio)
+	}
+	return s.Whats.SendAudioBase64(ctx, s.Number, base64.StdEncoding.EncodeToString(audio))
+}
+
+// summarizeForAudio resume a resposta para caber em maxChars falados.
+func summarizeForAudio(ctx context.Context, s *Session, text string, maxChars int) (string, error) {
+	if s.Cfg.ExtractionModel == "" {
+		return "", fmt.Errorf("sem modelo para resumo")
+	}
+	system := fmt.Sprintf("Resuma a mensagem de atendimento a seguir para ser falada em uma nota de voz de WhatsApp, "+
+		"em no máximo %d caracteres, no mesmo idioma e tom, sem listas nem links. Mantenha preços e próximos passos.", maxChars)
+	schema := objectSchema(map[string]any{"summary": map[string]any{"type": "string"}}, "summary")
+	schema["additionalProperties"] = false // exigido pelo modo strict
+	raw, err := s.AI.ChatJSON(ctx, s.Cfg.ExtractionModel, system, text, "audio_summary", schema)
+	if err != nil {
+		return "", err
+	}
+	var out struct {
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.Summary), nil
+}
+
+// speakable remove a formatação do WhatsApp para a leitura em voz alta.
+func speakable(text string) string {
+	t := whatsfmt.FromMarkdown(text)
+	t = strings.NewReplacer("*", "", "~", "", "`", "", "_", " ").Replace(t)
+	return strings.TrimSpace(t)
+}
+
+// SendAssistantReplyAudio fala a última resposta do assistente com a voz padrão.
 func SendAssistantReplyAudio(ctx context.Context, ai *clients.OpenAI, whats *clients.Whats, threadID, number string) error {
-    reply, err := ai.LastMessageText(ctx, threadID)
-    if err != nil || reply == "" { return err }
-    b64, err := ai.TextToSpeech(ctx, reply)
-    if err != nil { return err }
-    return whats.SendAudioBase64(ctx, number, b64)
+	reply, err := ai.LastMessageText(ctx, threadID)
+	if err != nil || reply == "" {
+		return err
+	}
+	opts := clients.DefaultSpeech
+	opts.Format = "opus"
+	audio, err := ai.Speech(ctx, speakable(reply), opts)
+	if err != nil {
+		return err
+	}
+	return whats.SendAudioBase64(ctx, number, base64.StdEncoding.EncodeToString(audio))
 }
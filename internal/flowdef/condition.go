@@ -0,0 +1,120 @@
+package flowdef
+
+import (
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pac-lead-agent/internal/document"
+	"pac-lead-agent/internal/shipping"
+)
+
+// Condition compara uma variável coletada com Value.
+// Op: eq, neq, contains, exists, empty, in (lista separada por vírgula), gt, lt, regex.
+type Condition struct {
+	Var   string `json:"var"`
+	Op    string `json:"op"`
+	Value string `json:"value,omitempty"`
+}
+
+// Eval avalia a condição (texto sem diferenciar maiúsculas).
+func (c Condition) Eval(vars map[string]string) bool {
+	v, ok := vars[c.Var]
+	lv, lw := strings.ToLower(strings.TrimSpace(v)), strings.ToLower(strings.TrimSpace(c.Value))
+	switch strings.ToLower(c.Op) {
+	case "", "eq":
+		return lv == lw
+	case "neq":
+		return lv != lw
+	case "contains":
+		return strings.Contains(lv, lw)
+	case "exists":
+		return ok && lv != ""
+	case "empty":
+		return lv == ""
+	case "in":
+		for _, x := range strings.Split(lw, ",") {
+			if strings.TrimSpace(x) == lv {
+				return true
+			}
+		}
+		return false
+	case "gt", "lt":
+		a, err1 := number(v)
+		b, err2 := number(c.Value)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if strings.ToLower(c.Op) == "gt" {
+			return a > b
+		}
+		return a < b
+	case "regex":
+		re, err := regexp.Compile(c.Value)
+		return err == nil && re.MatchString(v)
+	}
+	return false
+}
+
+// All avalia uma lista de condições (todas precisam valer).
+func All(conds []Condition, vars map[string]string) bool {
+	for _, c := range conds {
+		if !c.Eval(vars) {
+			return false
+		}
+	}
+	return true
+}
+
+// number aceita "1.299,90", "1299.90" e "R$ 10".
+func number(s string) (float64, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "R$"))
+	if strings.Contains(s, ",") {
+		s = strings.ReplaceAll(s, ".", "")
+		s = strings.ReplaceAll(s, ",", ".")
+	}
+	return strconv.ParseFloat(strings.TrimSpace(s), 64)
+}
+
+// Keyword informa se o texto contém alguma das palavras-chave.
+func Keyword(text string, keywords []string) bool {
+	t := strings.ToLower(text)
+	for _, k := range keywords {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" && strings.Contains(t, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// validator devolve a checagem de resposta de uma pergunta (nil se inválida).
+func validator(kind string) func(string) bool {
+	switch strings.ToLower(kind) {
+	case "email":
+		return func(s string) bool { _, err := mail.ParseAddress(strings.TrimSpace(s)); return err == nil }
+	case "number":
+		return func(s string) bool { _, err := number(s); return err == nil }
+	case "cep":
+		return func(s string) bool { _, err := shipping.NormalizeCEP(s); return err == nil }
+	case "document":
+		return func(s string) bool { _, ok := document.Validate(s); return ok }
+	}
+	re, err := regexp.Compile(kind)
+	if err != nil {
+		return nil
+	}
+	return re.MatchString
+}
+
+// Accepts aplica a validação da pergunta à resposta.
+func (n *Node) Accepts(answer string) bool {
+	if strings.TrimSpace(answer) == "" {
+		return false
+	}
+	if n.Validate == "" {
+		return true
+	}
+	v := validator(n.Validate)
+	return v != nil && v(answer)
+}
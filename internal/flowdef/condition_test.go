@@ -0,0 +1,86 @@
+package flowdef
+
+import "testing"
+
+func TestConditionEval(t *testing.T) {
+	vars := map[string]string{"nome": " Ana ", "orcamento": "1.299,90", "email": "ana@x.com", "vazio": ""}
+	cases := []struct {
+		c    Condition
+		want bool
+	}{
+		{Condition{Var: "nome", Value: "ana"}, true},
+		{Condition{Var: "nome", Op: "EQ", Value: "ANA"}, true},
+		{Condition{Var: "nome", Op: "neq", Value: "bia"}, true},
+		{Condition{Var: "email", Op: "contains", Value: "@X.COM"}, true},
+		{Condition{Var: "nome", Op: "exists"}, true},
+		{Condition{Var: "vazio", Op: "exists"}, false},
+		{Condition{Var: "faltando", Op: "exists"}, false},
+		{Condition{Var: "vazio", Op: "empty"}, true},
+		{Condition{Var: "faltando", Op: "empty"}, true},
+		{Condition{Var: "nome", Op: "in", Value: "bia, ana ,carla"}, true},
+		{Condition{Var: "nome", Op: "in", Value: "bia,carla"}, false},
+		{Condition{Var: "orcamento", Op: "gt", Value: "1000"}, true},
+		{Condition{Var: "orcamento", Op: "gt", Value: "R$ 1.500,00"}, false},
+		{Condition{Var: "orcamento", Op: "lt", Value: "1299.91"}, true},
+		{Condition{Var: "nome", Op: "gt", Value: "1"}, false}, // não numérico
+		{Condition{Var: "email", Op: "regex", Value: `^\w+@`}, true},
+		{Condition{Var: "email", Op: "regex", Value: `(`}, false},
+		{Condition{Var: "nome", Op: "desconhecido", Value: "ana"}, false},
+	}
+	for _, c := range cases {
+		if got := c.c.Eval(vars); got != c.want {
+			t.Errorf("Eval(%+v) = %v, want %v", c.c, got, c.want)
+		}
+	}
+	if !All(nil, vars) {
+		t.Error("All(nil) = false, want true")
+	}
+	if All([]Condition{cases[0].c, cases[5].c}, vars) {
+		t.Error("All com uma condição falsa = true")
+	}
+}
+
+func TestKeyword(t *testing.T) {
+	cases := []struct {
+		text     string
+		keywords []string
+		want     bool
+	}{
+		{"Quero ver ANÉIS", []string{"anéis", "colar"}, true},
+		{"quero ver brincos", []string{"anéis", "colar"}, false},
+		{"qualquer coisa", []string{" ", ""}, false},
+		{"qualquer coisa", nil, false},
+	}
+	for _, c := range cases {
+		if got := Keyword(c.text, c.keywords); got != c.want {
+			t.Errorf("Keyword(%q, %q) = %v, want %v", c.text, c.keywords, got, c.want)
+		}
+	}
+}
+
+func TestAccepts(t *testing.T) {
+	cases := []struct {
+		validate, answer string
+		want             bool
+	}{
+		{"", "qualquer", true},
+		{"", "   ", false},
+		{"email", "ana@x.com", true},
+		{"email", "ana", false},
+		{"number", "1.299,90", true},
+		{"number", "mil", false},
+		{"cep", "01310-100", true},
+		{"cep", "0131", false},
+		{"document", "529.982.247-25", true},
+		{"document", "529.982.247-24", false},
+		{`^\d{2}$`, "42", true},
+		{`^\d{2}$`, "423", false},
+		{`(`, "x", false},
+	}
+	for _, c := range cases {
+		n := &Node{Type: NodeQuestion, Validate: c.validate}
+		if got := n.Accepts(c.answer); got != c.want {
+			t.Errorf("Accepts(%q, %q) = %v, want %v", c.validate, c.answer, got, c.want)
+		}
+	}
+}
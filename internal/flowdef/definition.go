@@ -0,0 +1,275 @@
+// Package flowdef descreve os fluxos de conversa definidos pelo tenant na
+// Plataforma: um grafo de nós (mensagem, pergunta, decisão, IA, produtos,
+// atendimento humano, espera) e o estado de execução por conversa. A execução
+// fica no pacote flow.
+package flowdef
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tipos de nó.
+const (
+	NodeMessage  = "message"  // envia Text e segue
+	NodeQuestion = "question" // envia Text, espera a resposta e guarda em Variable
+	NodeBranch   = "branch"   // escolhe o próximo nó por condição ou intenção
+	NodeAI       = "ai"       // entrega a mensagem do lead ao assistente
+	NodeProducts = "products" // envia o carrossel de ProductIDs
+	NodeHandoff  = "handoff"  // transfere para atendente humano (encerra o fluxo)
+	NodeWait     = "wait"     // aguarda Seconds antes de seguir
+	NodeEnd      = "end"      // encerra o fluxo; a conversa segue com a IA
+)
+
+// Definition é o grafo de um fluxo.
+type Definition struct {
+	ID      string           `json:"id"`
+	Version string           `json:"version,omitempty"`
+	Start   string           `json:"start"`
+	Nodes   map[string]*Node `json:"-"`
+}
+
+// Node é um passo do fluxo; os campos usados dependem de Type.
+type Node struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// Text aceita {{variavel}} com as respostas já coletadas.
+	Text     string   `json:"text,omitempty"`
+	Choices  []string `json:"choices,omitempty"`  // question: botões de resposta
+	Variable string   `json:"variable,omitempty"` // question: onde guardar a resposta
+	// Validate (question) restringe a resposta: "email", "number", "cep", "document" ou uma regex.
+	Validate string `json:"validate,omitempty"`
+	Retry    string `json:"retry_text,omitempty"`
+	// Retries (question) é quantas vezes repetir a pergunta após respostas
+	// inválidas antes de passar a conversa para a IA (0 = padrão de 2).
+	Retries    int      `json:"max_retries,omitempty"`
+	Prompt     string   `json:"prompt,omitempty"` // ai: instrução enviada junto da mensagem
+	ProductIDs []string `json:"product_ids,omitempty"`
+	Reason     string   `json:"reason,omitempty"` // handoff
+	Seconds    int      `json:"seconds,omitempty"`
+	Branches   []Branch `json:"branches,omitempty"`
+	Default    string   `json:"default,omitempty"`
+	// Next é o nó seguinte. Em "ai", vazio mantém a conversa na IA.
+	Next string `json:"next,omitempty"`
+}
+
+// defaultRetries é o padrão de Node.Retries.
+const defaultRetries = 2
+
+// MaxRetries devolve o limite de perguntas repetidas do nó.
+func (n *Node) MaxRetries() int {
+	if n.Retries > 0 {
+		return n.Retries
+	}
+	return defaultRetries
+}
+
+// Branch leva a Next quando todas as condições valem, ou quando a última
+// mensagem do lead corresponde a Intent (palavras-chave ou classificação).
+type Branch struct {
+	If       []Condition `json:"if,omitempty"`
+	Intent   string      `json:"intent,omitempty"`
+	Keywords []string    `json:"keywords,omitempty"`
+	Next     string      `json:"next"`
+}
+
+// wire aceita os nós como lista ou como mapa por id.
+type wire struct {
+	ID      string          `json:"id"`
+	Version json.RawMessage `json:"version,omitempty"`
+	Start   string          `json:"start"`
+	Nodes   json.RawMessage `json:"nodes"`
+}
+
+func (d *Definition) UnmarshalJSON(b []byte) error {
+	var w wire
+	if err := json.Unmarshal(b, &w); err != nil {
+		return err
+	}
+	d.ID, d.Start = w.ID, w.Start
+	d.Version = strings.Trim(string(w.Version), `"`)
+	d.Nodes = map[string]*Node{}
+	var list []*Node
+	if err := json.Unmarshal(w.Nodes, &list); err != nil {
+		var byID map[string]*Node
+		if err := json.Unmarshal(w.Nodes, &byID); err != nil {
+			return fmt.Errorf("flow nodes: %w", err)
+		}
+		for id, n := range byID {
+			if n.ID == "" {
+				n.ID = id
+			}
+			list = append(list, n)
+		}
+	}
+	for _, n := range list {
+		if n != nil {
+			d.Nodes[n.ID] = n
+		}
+	}
+	if d.Start == "" && len(list) > 0 && list[0] != nil {
+		d.Start = list[0].ID
+	}
+	return nil
+}
+
+func (d Definition) MarshalJSON() ([]byte, error) {
+	nodes := make([]*Node, 0, len(d.Nodes))
+	for _, n := range d.Nodes {
+		nodes = append(nodes, n)
+	}
+	return json.Marshal(map[string]any{"id": d.ID, "version": d.Version, "start": d.Start, "nodes": nodes})
+}
+
+var ErrInvalid = errors.New("fluxo inválido")
+
+// Validate confere tipos e ligações entre nós.
+func (d *Definition) Validate() error {
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("%w: sem nós", ErrInvalid)
+	}
+	if d.Nodes[d.Start] == nil {
+		return fmt.Errorf("%w: nó inicial %q não existe", ErrInvalid, d.Start)
+	}
+	link := func(from, to string) error {
+		if to != "" && d.Nodes[to] == nil {
+			return fmt.Errorf("%w: %s aponta para %q, que não existe", ErrInvalid, from, to)
+		}
+		return nil
+	}
+	for id, n := range d.Nodes {
+		switch n.Type {
+		case NodeMessage, NodeAI, NodeProducts, NodeHandoff, NodeEnd:
+		case NodeWait:
+			if n.Seconds <= 0 {
+				return fmt.Errorf("%w: %s (wait) precisa de seconds > 0", ErrInvalid, id)
+			}
+		case NodeQuestion:
+			if n.Validate != "" && validator(n.Validate) == nil {
+				return fmt.Errorf("%w: %s tem validação inválida", ErrInvalid, id)
+			}
+		case NodeBranch:
+			for _, b := range n.Branches {
+				if err := link(id, b.Next); err != nil {
+					return err
+				}
+			}
+			if err := link(id, n.Default); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("%w: %s tem tipo desconhecido %q", ErrInvalid, id, n.Type)
+		}
+		if err := link(id, n.Next); err != nil {
+			return err
+		}
+	}
+	return d.checkCycles()
+}
+
+// edges devolve os destinos possíveis do nó.
+func (n *Node) edges() []string {
+	out := []string{n.Next}
+	if n.Type == NodeBranch {
+		out = append(out, n.Default)
+		for _, b := range n.Branches {
+			out = append(out, b.Next)
+		}
+	}
+	return out
+}
+
+// pauses informa se a execução só segue depois de uma resposta do lead. Espera
+// não conta: um laço com wait continuaria mandando mensagens a quem não responde.
+func (n *Node) pauses() bool {
+	return n.Type == NodeQuestion
+}
+
+// checkCycles rejeita laços sem pergunta (ex.: message → wait → message), que
+// enviariam mensagens sem fim, em sequência ou a cada espera vencida.
+func (d *Definition) checkCycles() error {
+	const (
+		unseen = iota
+		visiting
+		done
+	)
+	mark := map[string]int{}
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch mark[id] {
+		case visiting:
+			return fmt.Errorf("%w: laço sem pergunta passando por %s", ErrInvalid, id)
+		case done:
+			return nil
+		}
+		mark[id] = visiting
+		n := d.Nodes[id]
+		if !n.pauses() {
+			for _, to := range n.edges() {
+				if to == "" || d.Nodes[to] == nil {
+					continue
+				}
+				if err := visit(to); err != nil {
+					return err
+				}
+			}
+		}
+		mark[id] = done
+		return nil
+	}
+	// ordem estável para a mensagem de erro
+	ids := make([]string, 0, len(d.Nodes))
+	for id := range d.Nodes {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Default é o fluxo implícito: um único nó de IA (comportamento original).
+func Default() *Definition {
+	return &Definition{ID: "default", Start: "ai", Nodes: map[string]*Node{"ai": {ID: "ai", Type: NodeAI}}}
+}
+
+// State é a execução do fluxo em uma conversa.
+type State struct {
+	FlowID  string            `json:"flow_id"`
+	Version string            `json:"version,omitempty"`
+	Node    string            `json:"node"`
+	Vars    map[string]string `json:"vars,omitempty"`
+	// Awaiting indica que Node (question) espera a resposta do lead.
+	Awaiting bool `json:"awaiting,omitempty"`
+	// Retries conta as respostas inválidas à pergunta atual.
+	Retries   int       `json:"retries,omitempty"`
+	WaitUntil time.Time `json:"wait_until,omitempty"`
+	Done      bool      `json:"done,omitempty"`
+}
+
+// Matches informa se o estado pertence à versão atual do fluxo.
+func (s *State) Matches(d *Definition) bool {
+	return s != nil && s.FlowID == d.ID && s.Version == d.Version && d.Nodes[s.Node] != nil
+}
+
+// NewState inicia a execução no nó inicial.
+func NewState(d *Definition) *State {
+	return &State{FlowID: d.ID, Version: d.Version, Node: d.Start, Vars: map[string]string{}}
+}
+
+var varPattern = regexp.MustCompile(`\{\{\s*([\w.-]+)\s*\}\}`)
+
+// Render substitui {{variavel}} pelas respostas coletadas.
+func Render(text string, vars map[string]string) string {
+	return varPattern.ReplaceAllStringFunc(text, func(m string) string {
+		return vars[varPattern.FindStringSubmatch(m)[1]]
+	})
+}
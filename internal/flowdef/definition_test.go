@@ -0,0 +1,142 @@
+package flowdef
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestUnmarshal(t *testing.T) {
+	cases := []struct {
+		name, in string
+		start    string
+		version  string
+		nodes    int
+	}{
+		{"lista", `{"id":"f","version":3,"nodes":[{"id":"a","type":"message","next":"b"},{"id":"b","type":"end"}]}`, "a", "3", 2},
+		{"mapa por id", `{"id":"f","version":"v2","start":"b","nodes":{"a":{"type":"end"},"b":{"type":"message","next":"a"}}}`, "b", "v2", 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var d Definition
+			if err := json.Unmarshal([]byte(c.in), &d); err != nil {
+				t.Fatal(err)
+			}
+			if d.Start != c.start || d.Version != c.version || len(d.Nodes) != c.nodes {
+				t.Errorf("start=%q version=%q nós=%d; want %q %q %d", d.Start, d.Version, len(d.Nodes), c.start, c.version, c.nodes)
+			}
+			if err := d.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+	var d Definition
+	if err := json.Unmarshal([]byte(`{"id":"f","nodes":"x"}`), &d); err == nil {
+		t.Error("nodes inválido: esperava erro")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		ok   bool
+	}{
+		{"padrão", `{"id":"d","start":"ai","nodes":[{"id":"ai","type":"ai"}]}`, true},
+		{"pergunta com branch", `{"id":"f","nodes":[
+			{"id":"q","type":"question","variable":"v","validate":"email","next":"b"},
+			{"id":"b","type":"branch","branches":[{"if":[{"var":"v","op":"exists"}],"next":"fim"}],"default":"h"},
+			{"id":"h","type":"handoff"},
+			{"id":"fim","type":"end"}]}`, true},
+		{"sem nós", `{"id":"f","nodes":[]}`, false},
+		{"início inexistente", `{"id":"f","start":"x","nodes":[{"id":"a","type":"end"}]}`, false},
+		{"tipo desconhecido", `{"id":"f","nodes":[{"id":"a","type":"loop"}]}`, false},
+		{"next inexistente", `{"id":"f","nodes":[{"id":"a","type":"message","next":"x"}]}`, false},
+		{"branch inexistente", `{"id":"f","nodes":[{"id":"a","type":"branch","branches":[{"next":"x"}]}]}`, false},
+		{"default inexistente", `{"id":"f","nodes":[{"id":"a","type":"branch","default":"x"}]}`, false},
+		{"regex inválida", `{"id":"f","nodes":[{"id":"a","type":"question","validate":"("}]}`, false},
+		{"auto-laço", `{"id":"f","nodes":[{"id":"a","type":"message","next":"a"}]}`, false},
+		{"laço de mensagens", `{"id":"f","nodes":[
+			{"id":"a","type":"message","next":"b"},
+			{"id":"b","type":"message","next":"a"}]}`, false},
+		{"laço pelo default do branch", `{"id":"f","nodes":[
+			{"id":"a","type":"message","next":"b"},
+			{"id":"b","type":"branch","branches":[{"intent":"sair","next":"fim"}],"default":"a"},
+			{"id":"fim","type":"end"}]}`, false},
+		{"laço com pergunta", `{"id":"f","nodes":[
+			{"id":"q","type":"question","variable":"v","next":"b"},
+			{"id":"b","type":"branch","branches":[{"if":[{"var":"v","value":"sim"}],"next":"fim"}],"default":"m"},
+			{"id":"m","type":"message","next":"q"},
+			{"id":"fim","type":"end"}]}`, true},
+		{"laço com espera", `{"id":"f","nodes":[
+			{"id":"m","type":"message","next":"w"},
+			{"id":"w","type":"wait","seconds":60,"next":"m"}]}`, false},
+		{"laço com espera e pergunta", `{"id":"f","nodes":[
+			{"id":"m","type":"message","next":"w"},
+			{"id":"w","type":"wait","seconds":60,"next":"q"},
+			{"id":"q","type":"question","variable":"v","next":"m"}]}`, true},
+		{"espera sem seconds", `{"id":"f","nodes":[{"id":"w","type":"wait","next":"fim"},{"id":"fim","type":"end"}]}`, false},
+		{"espera negativa", `{"id":"f","nodes":[{"id":"w","type":"wait","seconds":-5}]}`, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var d Definition
+			if err := json.Unmarshal([]byte(c.in), &d); err != nil {
+				t.Fatal(err)
+			}
+			err := d.Validate()
+			if (err == nil) != c.ok {
+				t.Fatalf("Validate = %v, ok = %v", err, c.ok)
+			}
+			if err != nil && !errors.Is(err, ErrInvalid) {
+				t.Errorf("erro %v não embrulha ErrInvalid", err)
+			}
+		})
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	cases := []struct{ retries, want int }{{0, 2}, {-1, 2}, {1, 1}, {5, 5}}
+	for _, c := range cases {
+		if got := (&Node{Retries: c.retries}).MaxRetries(); got != c.want {
+			t.Errorf("MaxRetries(%d) = %d, want %d", c.retries, got, c.want)
+		}
+	}
+}
+
+func TestStateMatches(t *testing.T) {
+	d := &Definition{ID: "f", Version: "2", Start: "a", Nodes: map[string]*Node{"a": {ID: "a", Type: NodeEnd}}}
+	s := NewState(d)
+	if !s.Matches(d) {
+		t.Error("estado novo não corresponde ao fluxo")
+	}
+	cases := []struct {
+		name string
+		s    *State
+	}{
+		{"nil", nil},
+		{"outro fluxo", &State{FlowID: "g", Version: "2", Node: "a"}},
+		{"outra versão", &State{FlowID: "f", Version: "1", Node: "a"}},
+		{"nó removido", &State{FlowID: "f", Version: "2", Node: "b"}},
+	}
+	for _, c := range cases {
+		if c.s.Matches(d) {
+			t.Errorf("%s: Matches = true", c.name)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	vars := map[string]string{"nome": "Ana", "produto.cor": "azul"}
+	cases := []struct{ in, want string }{
+		{"Olá, {{nome}}!", "Olá, Ana!"},
+		{"Olá, {{ nome }}! Cor: {{produto.cor}}", "Olá, Ana! Cor: azul"},
+		{"Sem {{faltando}}valor", "Sem valor"},
+		{"Sem variáveis", "Sem variáveis"},
+	}
+	for _, c := range cases {
+		if got := Render(c.in, vars); got != c.want {
+			t.Errorf("Render(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
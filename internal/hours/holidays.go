@@ -0,0 +1,65 @@
+package hours
+
+import "time"
+
+// Holiday é um feriado em uma data.
+type Holiday struct {
+	Date time.Time
+	Name string
+}
+
+// fixedHolidays são os feriados nacionais de data fixa (Lei 662/1949 e
+// posteriores; Consciência Negra a partir de 2024).
+var fixedHolidays = []struct {
+	month time.Month
+	day   int
+	name  string
+	since int
+}{
+	{time.January, 1, "Confraternização Universal", 0},
+	{time.April, 21, "Tiradentes", 0},
+	{time.May, 1, "Dia do Trabalho", 0},
+	{time.September, 7, "Independência do Brasil", 0},
+	{time.October, 12, "Nossa Senhora Aparecida", 0},
+	{time.November, 2, "Finados", 0},
+	{time.November, 15, "Proclamação da República", 0},
+	{time.November, 20, "Dia Nacional de Zumbi e da Consciência Negra", 2024},
+	{time.December, 25, "Natal", 0},
+}
+
+// NationalHolidays devolve os feriados nacionais do ano. Com optional, inclui
+// os pontos facultativos mais comuns (Carnaval e Corpus Christi).
+func NationalHolidays(year int, optional bool, loc *time.Location) []Holiday {
+	var out []Holiday
+	for _, f := range fixedHolidays {
+		if year >= f.since {
+			out = append(out, Holiday{time.Date(year, f.month, f.day, 0, 0, 0, 0, loc), f.name})
+		}
+	}
+	easter := Easter(year, loc)
+	out = append(out, Holiday{easter.AddDate(0, 0, -2), "Sexta-feira Santa"})
+	if optional {
+		out = append(out,
+			Holiday{easter.AddDate(0, 0, -48), "Carnaval"},
+			Holiday{easter.AddDate(0, 0, -47), "Carnaval"},
+			Holiday{easter.AddDate(0, 0, 60), "Corpus Christi"},
+		)
+	}
+	return out
+}
+
+// Easter calcula o domingo de Páscoa (algoritmo de Meeus/Jones/Butcher).
+func Easter(year int, loc *time.Location) time.Time {
+	a := year % 19
+	b, c := year/100, year%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, loc)
+}
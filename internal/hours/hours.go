@@ -0,0 +1,182 @@
+// Package hours calcula o horário de atendimento do tenant: grade semanal,
+// feriados nacionais e fechamentos avulsos, em um fuso próprio.
+package hours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule é a configuração de settings["business_hours"].
+type Schedule struct {
+	Timezone string `json:"timezone"`
+	// Weekly usa chaves "mon".."sun" (ou "seg".."dom") e faixas "08:00-18:00".
+	Weekly           map[string][]string `json:"weekly"`
+	Holidays         *bool               `json:"holidays"`          // feriados nacionais (padrão ligado)
+	OptionalHolidays bool                `json:"optional_holidays"` // Carnaval e Corpus Christi
+	Closures         []Closure           `json:"closures"`
+}
+
+// Closure é um fechamento avulso: um dia (Date) ou um período (From..To),
+// datas no formato 2006-01-02.
+type Closure struct {
+	Date   string `json:"date"`
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Reason string `json:"reason"`
+}
+
+// Status é a situação em um instante.
+type Status struct {
+	Open bool
+	// NextOpen é a próxima abertura (zero se aberto ou sem grade).
+	NextOpen time.Time
+	// Reason explica o fechamento ("feriado: Natal", "fora do horário").
+	Reason string
+}
+
+var dayKeys = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+	"dom": time.Sunday, "seg": time.Monday, "ter": time.Tuesday, "qua": time.Wednesday,
+	"qui": time.Thursday, "sex": time.Friday, "sab": time.Saturday, "sáb": time.Saturday,
+}
+
+// Configured informa se o tenant definiu uma grade (sem grade = sempre aberto).
+func (s Schedule) Configured() bool {
+	return len(s.Weekly) > 0
+}
+
+// Location devolve o fuso da grade (padrão America/Sao_Paulo).
+func (s Schedule) Location(def *time.Location) *time.Location {
+	if s.Timezone != "" {
+		if loc, err := time.LoadLocation(s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return def
+}
+
+type span struct{ from, to int } // minutos desde a meia-noite
+
+func (s Schedule) spans(day time.Weekday) []span {
+	var out []span
+	for k, ranges := range s.Weekly {
+		if wd, ok := dayKeys[strings.ToLower(strings.TrimSpace(k))]; !ok || wd != day {
+			continue
+		}
+		for _, r := range ranges {
+			a, b, ok := strings.Cut(r, "-")
+			if !ok {
+				continue
+			}
+			from, ok1 := clock(a)
+			to, ok2 := clock(b)
+			if ok1 && ok2 && to > from {
+				out = append(out, span{from, to})
+			}
+		}
+	}
+	return out
+}
+
+func clock(s string) (int, bool) {
+	h, m, ok := strings.Cut(strings.TrimSpace(s), ":")
+	if !ok {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || hh > 24 || mm < 0 || mm > 59 || hh*60+mm > 24*60 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
+
+// closed devolve o motivo se o dia inteiro estiver fechado (feriado/fechamento).
+func (s Schedule) closed(day time.Time) string {
+	date := day.Format("2006-01-02")
+	for _, c := range s.Closures {
+		if c.Date == date || (c.From != "" && c.To != "" && date >= c.From && date <= c.To) {
+			if c.Reason != "" {
+				return c.Reason
+			}
+			return "fechamento programado"
+		}
+	}
+	if s.Holidays == nil || *s.Holidays {
+		for _, h := range NationalHolidays(day.Year(), s.OptionalHolidays, day.Location()) {
+			if h.Date.Format("2006-01-02") == date {
+				return "feriado: " + h.Name
+			}
+		}
+	}
+	return ""
+}
+
+// At calcula a situação no instante t (no fuso da grade).
+func (s Schedule) At(t time.Time, def *time.Location) Status {
+	if !s.Configured() {
+		return Status{Open: true}
+	}
+	t = t.In(s.Location(def))
+	reason := s.closed(t)
+	m := t.Hour()*60 + t.Minute()
+	if reason == "" {
+		for _, sp := range s.spans(t.Weekday()) {
+			if m >= sp.from && m < sp.to {
+				return Status{Open: true}
+			}
+		}
+		reason = "fora do horário de atendimento"
+	}
+	return Status{Reason: reason, NextOpen: s.nextOpen(t)}
+}
+
+// nextOpen procura a próxima abertura nas próximas semanas.
+func (s Schedule) nextOpen(t time.Time) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for i := 0; i < 60; i++ {
+		d := day.AddDate(0, 0, i)
+		if s.closed(d) != "" {
+			continue
+		}
+		best := -1
+		for _, sp := range s.spans(d.Weekday()) {
+			if i == 0 && sp.from <= t.Hour()*60+t.Minute() {
+				continue
+			}
+			if best < 0 || sp.from < best {
+				best = sp.from
+			}
+		}
+		if best >= 0 {
+			return d.Add(time.Duration(best) * time.Minute)
+		}
+	}
+	return time.Time{}
+}
+
+var weekdayPT = []string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"}
+
+// Describe escreve a abertura em português relativo a now ("hoje às 14:00",
+// "amanhã às 08:00", "segunda-feira (03/11) às 08:00").
+func Describe(open, now time.Time) string {
+	if open.IsZero() {
+		return ""
+	}
+	now = now.In(open.Location())
+	y1, m1, d1 := now.Date()
+	today := time.Date(y1, m1, d1, 0, 0, 0, 0, open.Location())
+	days := int(open.Sub(today).Hours() / 24)
+	clock := open.Format("15:04")
+	switch days {
+	case 0:
+		return "hoje às " + clock
+	case 1:
+		return "amanhã às " + clock
+	}
+	return fmt.Sprintf("%s (%s) às %s", weekdayPT[open.Weekday()], open.Format("02/01"), clock)
+}
@@ -0,0 +1,134 @@
+package hours
+
+import (
+	"testing"
+	"time"
+)
+
+// fuso fixo de Brasília: não depende do tzdata do sistema
+var brt = time.FixedZone("BRT", -3*3600)
+
+func at(date, clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", date+" "+clock, brt)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestEaster(t *testing.T) {
+	cases := []struct {
+		year int
+		want string
+	}{
+		{1818, "1818-03-22"}, // a mais cedo possível
+		{2000, "2000-04-23"},
+		{2019, "2019-04-21"},
+		{2024, "2024-03-31"},
+		{2025, "2025-04-20"},
+		{2026, "2026-04-05"},
+		{2038, "2038-04-25"}, // a mais tarde possível
+	}
+	for _, c := range cases {
+		if got := Easter(c.year, brt).Format("2006-01-02"); got != c.want {
+			t.Errorf("Easter(%d) = %s, want %s", c.year, got, c.want)
+		}
+	}
+}
+
+func TestNationalHolidays(t *testing.T) {
+	cases := []struct {
+		year     int
+		optional bool
+		want     int
+	}{
+		{2023, false, 9}, // sem Consciência Negra
+		{2024, false, 10},
+		{2024, true, 13},
+	}
+	for _, c := range cases {
+		if got := len(NationalHolidays(c.year, c.optional, brt)); got != c.want {
+			t.Errorf("NationalHolidays(%d, %v) = %d feriados, want %d", c.year, c.optional, got, c.want)
+		}
+	}
+}
+
+func TestScheduleAt(t *testing.T) {
+	off := false
+	weekly := map[string][]string{
+		"mon": {"08:00-12:00", "13:00-18:00"},
+		"tue": {"08:00-12:00", "13:00-18:00"},
+		"qua": {"13:00-18:00", "08:00-12:00"},
+		"thu": {"08:00-12:00", "13:00-18:00"},
+		"fri": {"08:00-12:00", "13:00-18:00"},
+		"Sáb": {"09:00-13:00", "inválido", "14:00-13:00"},
+	}
+	cases := []struct {
+		name     string
+		s        Schedule
+		now      time.Time
+		open     bool
+		reason   string
+		nextOpen time.Time
+	}{
+		{"sem grade", Schedule{}, at("2025-12-25", "03:00"), true, "", time.Time{}},
+		{"dentro do horário", Schedule{Weekly: weekly}, at("2025-10-15", "10:00"), true, "", time.Time{}},
+		{"início da faixa", Schedule{Weekly: weekly}, at("2025-10-15", "08:00"), true, "", time.Time{}},
+		{"almoço", Schedule{Weekly: weekly}, at("2025-10-15", "12:30"), false, "fora do horário de atendimento", at("2025-10-15", "13:00")},
+		{"fim do dia", Schedule{Weekly: weekly}, at("2025-10-15", "18:00"), false, "fora do horário de atendimento", at("2025-10-16", "08:00")},
+		{"madrugada", Schedule{Weekly: weekly}, at("2025-10-15", "06:00"), false, "fora do horário de atendimento", at("2025-10-15", "08:00")},
+		{"sábado à tarde", Schedule{Weekly: weekly}, at("2025-10-18", "14:00"), false, "fora do horário de atendimento", at("2025-10-20", "08:00")},
+		{"domingo", Schedule{Weekly: weekly}, at("2025-10-19", "10:00"), false, "fora do horário de atendimento", at("2025-10-20", "08:00")},
+		{"Natal", Schedule{Weekly: weekly}, at("2025-12-25", "10:00"), false, "feriado: Natal", at("2025-12-26", "08:00")},
+		{"Sexta-feira Santa", Schedule{Weekly: weekly}, at("2025-04-18", "10:00"), false, "feriado: Sexta-feira Santa", at("2025-04-19", "09:00")},
+		{"Consciência Negra antes de 2024", Schedule{Weekly: weekly}, at("2023-11-20", "10:00"), true, "", time.Time{}},
+		{"Consciência Negra", Schedule{Weekly: weekly}, at("2024-11-20", "10:00"), false, "feriado: Dia Nacional de Zumbi e da Consciência Negra", at("2024-11-21", "08:00")},
+		{"feriados desligados", Schedule{Weekly: weekly, Holidays: &off}, at("2025-12-25", "10:00"), true, "", time.Time{}},
+		{"Carnaval sem facultativos", Schedule{Weekly: weekly}, at("2025-03-03", "10:00"), true, "", time.Time{}},
+		{"Carnaval", Schedule{Weekly: weekly, OptionalHolidays: true}, at("2025-03-03", "10:00"), false, "feriado: Carnaval", at("2025-03-05", "08:00")},
+		{"fechamento de um dia", Schedule{Weekly: weekly, Closures: []Closure{{Date: "2025-10-15", Reason: "inventário"}}},
+			at("2025-10-15", "10:00"), false, "inventário", at("2025-10-16", "08:00")},
+		{"fechamento por período", Schedule{Weekly: weekly, Closures: []Closure{{From: "2025-10-15", To: "2025-10-17"}}},
+			at("2025-10-16", "10:00"), false, "fechamento programado", at("2025-10-18", "09:00")},
+		{"nunca abre", Schedule{Weekly: map[string][]string{"mon": {"18:00-08:00"}}}, at("2025-10-15", "10:00"), false, "fora do horário de atendimento", time.Time{}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			st := c.s.At(c.now, brt)
+			if st.Open != c.open || st.Reason != c.reason {
+				t.Errorf("At = open %v, %q; want open %v, %q", st.Open, st.Reason, c.open, c.reason)
+			}
+			if !st.NextOpen.Equal(c.nextOpen) {
+				t.Errorf("NextOpen = %v, want %v", st.NextOpen, c.nextOpen)
+			}
+		})
+	}
+}
+
+func TestScheduleAtConvertsTimezone(t *testing.T) {
+	s := Schedule{Weekly: map[string][]string{"wed": {"08:00-18:00"}}}
+	// 10:30 UTC = 07:30 em Brasília
+	st := s.At(time.Date(2025, 10, 15, 10, 30, 0, 0, time.UTC), brt)
+	if st.Open || !st.NextOpen.Equal(at("2025-10-15", "08:00")) {
+		t.Errorf("At = %+v, want fechado até 08:00", st)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	now := at("2025-10-18", "14:00") // sábado
+	cases := []struct {
+		open time.Time
+		want string
+	}{
+		{time.Time{}, ""},
+		{at("2025-10-18", "16:00"), "hoje às 16:00"},
+		{at("2025-10-19", "08:00"), "amanhã às 08:00"},
+		{at("2025-10-20", "08:00"), "segunda-feira (20/10) às 08:00"},
+		{at("2025-11-03", "09:30"), "segunda-feira (03/11) às 09:30"},
+	}
+	for _, c := range cases {
+		if got := Describe(c.open, now); got != c.want {
+			t.Errorf("Describe(%v) = %q, want %q", c.open, got, c.want)
+		}
+	}
+}
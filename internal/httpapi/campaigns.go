@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"pac-lead-agent/internal/campaign"
+	"pac-lead-agent/internal/flow"
+	"pac-lead-agent/internal/state"
+)
+
+// campaignRequest é o corpo de POST /api/campaigns. Instância e tenant podem
+// vir no corpo ou nos headers X-Instance-ID/X-Instance-Token/X-Org-ID/X-Flow-ID.
+type campaignRequest struct {
+	Name          string               `json:"name"`
+	OrgID         string               `json:"org_id"`
+	FlowID        string               `json:"flow_id"`
+	InstanceID    string               `json:"instance_id"`
+	InstanceToken string               `json:"instance_token"`
+	Template      string               `json:"template"`
+	ProductIDs    []string             `json:"product_ids"`
+	Recipients    []campaign.Recipient `json:"recipients"`
+	MinInterval   int                  `json:"min_interval_seconds"`
+	MaxInterval   int                  `json:"max_interval_seconds"`
+}
+
+// campaignView é a resposta dos endpoints de status.
+type campaignView struct {
+	campaign.Campaign
+	Progress campaign.Progress `json:"progress"`
+}
+
+func viewOf(c campaign.Campaign, withRecipients bool) campaignView {
+	v := campaignView{Campaign: c, Progress: c.Progress()}
+	if !withRecipients {
+		v.Recipients = nil
+	}
+	return v
+}
+
+// campaigns trata /api/campaigns: POST cria, GET lista (filtro ?org_id=).
+func (h *handler) campaigns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		var req campaignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "bad payload"})
+			return
+		}
+		c := campaign.Campaign{
+			Name:          strings.TrimSpace(req.Name),
+			OrgID:         firstNonEmpty(req.OrgID, r.Header.Get("X-Org-ID")),
+			FlowID:        firstNonEmpty(req.FlowID, r.Header.Get("X-Flow-ID")),
+			InstanceID:    firstNonEmpty(req.InstanceID, r.Header.Get("X-Instance-ID")),
+			InstanceToken: firstNonEmpty(req.InstanceToken, r.Header.Get("X-Instance-Token")),
+			Template:      req.Template,
+			ProductIDs:    req.ProductIDs,
+			Recipients:    req.Recipients,
+			MinInterval:   req.MinInterval,
+			MaxInterval:   req.MaxInterval,
+		}
+		c, err := flow.StartCampaign(r.Context(), c)
+		if err != nil {
+			status := http.StatusInternalServerError
+			for _, e := range []error{campaign.ErrEmptyTemplate, campaign.ErrNoInstance, campaign.ErrNoRecipients, campaign.ErrTooManyTargets} {
+				if errors.Is(err, e) {
+					status = http.StatusBadRequest
+				}
+			}
+			writeJSON(w, status, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusAccepted, viewOf(c, false))
+	case http.MethodGet:
+		list, err := campaign.List(r.Context(), state.Default(), strings.TrimSpace(r.URL.Query().Get("org_id")))
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		out := make([]campaignView, 0, len(list))
+		for _, c := range list {
+			out = append(out, viewOf(c, false))
+		}
+		writeJSON(w, http.StatusOK, out)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+	}
+}
+
+// campaign trata /api/campaigns/<id> (GET: progresso e status por
+// destinatário) e /api/campaigns/<id>/cancel (POST).
+func (h *handler) campaign(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/campaigns/"), "/"), "/")
+	id := parts[0]
+	if id == "" || len(parts) > 2 {
+		http.NotFound(w, r)
+		return
+	}
+	var (
+		c   campaign.Campaign
+		err error
+	)
+	switch {
+	case len(parts) == 1 && r.Method == http.MethodGet:
+		c, err = campaign.Load(r.Context(), state.Default(), id)
+	case len(parts) == 2 && parts[1] == "cancel" && r.Method == http.MethodPost:
+		c, err = flow.CancelCampaign(r.Context(), id)
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if errors.Is(err, campaign.ErrNotFound) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, viewOf(c, true))
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,27 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"pac-lead-agent/internal/flow"
+)
+
+// handoffRelease trata POST /api/handoff/release: o atendente devolve a
+// conversa ao bot sem esperar o fim da pausa.
+func (h *handler) handoffRelease(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	c, _, ok := readContact(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "number obrigatório"})
+		return
+	}
+	released, err := flow.ReleaseHandoff(r.Context(), h.cfg, c)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "released": released})
+}
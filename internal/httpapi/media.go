@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+	"pac-lead-agent/internal/media"
+)
+
+// imageCache é compartilhado entre requisições do proxy de mídia.
+var imageCache = media.NewCache(6*time.Hour, 512)
+
+// mediaProduct serve /media/products/<tenant>/<id>, onde <tenant> é o id_empresa
+// do PacLead. Busca a imagem no :8889, normaliza (JPEG, ≤1024px, ≤5MB) e devolve
+// por HTTPS para que o WhatsApp consiga baixar os cards do carrossel.
+func (h *handler) mediaProduct(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	rest := strings.TrimPrefix(r.URL.Path, "/media/products/")
+	// aceita sufixo de extensão (ex.: 123.jpg) pois alguns gateways exigem
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	tenant := parts[0]
+	id := strings.TrimSuffix(parts[1], ".jpg")
+	if !isNumeric(tenant) || !isNumeric(id) {
+		http.NotFound(w, r)
+		return
+	}
+
+	key := tenant + "/" + id
+	data, ctype, ok := imageCache.Get(key)
+	if !ok {
+		ctx, cancel := context.WithTimeout(r.Context(), 20*time.Second)
+		defer cancel()
+		pl := clients.NewPacLead(h.cfg.PacLeadBaseURL, h.cfg.PacLeadCRMBaseURL, h.cfg.PlatformBaseURL)
+		raw, _, err := pl.ProdutoImagem(ctx, id, tenant)
+		if err != nil {
+			log.Println("media proxy:", err, "key:", key)
+			http.Error(w, "upstream error", http.StatusBadGateway)
+			return
+		}
+		data, ctype, err = media.Normalize(raw)
+		if err != nil {
+			log.Println("media normalize:", err, "key:", key)
+			http.NotFound(w, r)
+			return
+		}
+		imageCache.Set(key, data, ctype)
+	}
+
+	w.Header().Set("Content-Type", ctype)
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	w.Header().Set("Cache-Control", "public, max-age=21600")
+	if r.Method == http.MethodHead {
+		return
+	}
+	_, _ = w.Write(data)
+}
+
+func isNumeric(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
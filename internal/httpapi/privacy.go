@@ -0,0 +1,105 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"pac-lead-agent/internal/flow"
+)
+
+// contactRequest identifica o titular: número e tenant (cnpj ou org/flow,
+// também aceitos nos headers X-Org-ID/X-Flow-ID e na query-string).
+type contactRequest struct {
+	Number string `json:"number"`
+	CNPJ   string `json:"cnpj"`
+	OrgID  string `json:"org_id"`
+	FlowID string `json:"flow_id"`
+	Reason string `json:"reason"`
+}
+
+func readContact(r *http.Request) (flow.Contact, contactRequest, bool) {
+	var req contactRequest
+	if r.Method != http.MethodGet && r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&req)
+	}
+	q := r.URL.Query()
+	req.Number = firstNonEmpty(req.Number, q.Get("number"), q.Get("numero"))
+	req.CNPJ = firstNonEmpty(req.CNPJ, q.Get("cnpj"))
+	req.OrgID = firstNonEmpty(req.OrgID, r.Header.Get("X-Org-ID"), q.Get("org_id"))
+	req.FlowID = firstNonEmpty(req.FlowID, r.Header.Get("X-Flow-ID"), q.Get("flow_id"))
+	c := flow.Contact{
+		Opts:   flow.Options{OrgID: req.OrgID, FlowID: req.FlowID},
+		CNPJ:   strings.TrimSpace(req.CNPJ),
+		Number: strings.TrimSpace(req.Number),
+	}
+	return c, req, c.Number != ""
+}
+
+// lgpdExport trata GET /api/lgpd/export: tudo o que guardamos sobre o número.
+func (h *handler) lgpdExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	c, _, ok := readContact(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "number obrigatório"})
+		return
+	}
+	out, err := flow.ExportContact(r.Context(), h.cfg, c)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// lgpdDelete trata POST /api/lgpd/delete: apaga os dados do número.
+func (h *handler) lgpdDelete(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost && r.Method != http.MethodDelete {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	c, _, ok := readContact(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "number obrigatório"})
+		return
+	}
+	steps := flow.EraseContact(r.Context(), h.cfg, c)
+	// complete só quando todas as etapas apagaram de fato
+	complete := true
+	for _, v := range steps {
+		complete = complete && v == "ok"
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"number": c.Number, "complete": complete, "steps": steps})
+}
+
+// optOut trata /api/optout: GET consulta, POST registra e DELETE remove o
+// opt-out do número.
+func (h *handler) optOut(w http.ResponseWriter, r *http.Request) {
+	c, req, ok := readContact(r)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "number obrigatório"})
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		rec, out := flow.GetOptOut(r.Context(), h.cfg, c)
+		resp := map[string]any{"number": c.Number, "opt_out": out}
+		if out {
+			resp["record"] = rec
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	case http.MethodPost, http.MethodDelete:
+	default:
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	if err := flow.SetOptOut(r.Context(), h.cfg, c, r.Method == http.MethodPost, req.Reason); err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ok": true, "opt_out": r.Method == http.MethodPost})
+}
@@ -0,0 +1,113 @@
+package httpapi
+
+import (
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/phone"
+	"pac-lead-agent/internal/transcript"
+)
+
+// transcriptQuery lê os filtros da query-string: cnpj, org_id (ou header
+// X-Org-ID), number, direction (in/out), since/until (RFC 3339 ou AAAA-MM-DD),
+// limit e offset.
+func transcriptQuery(r *http.Request) (transcript.Query, error) {
+	v := r.URL.Query()
+	q := transcript.Query{
+		Tenant:    strings.TrimSpace(v.Get("cnpj")),
+		OrgID:     firstNonEmpty(v.Get("org_id"), r.Header.Get("X-Org-ID")),
+		Direction: strings.TrimSpace(v.Get("direction")),
+	}
+	if n := strings.TrimSpace(v.Get("number")); n != "" {
+		q.Number = phone.Canonical(n)
+	}
+	var err error
+	if q.Since, err = parseDate(v.Get("since")); err != nil {
+		return q, err
+	}
+	if q.Until, err = parseDate(v.Get("until")); err != nil {
+		return q, err
+	}
+	q.Limit, _ = strconv.Atoi(v.Get("limit"))
+	if off, _ := strconv.Atoi(v.Get("offset")); off > 0 {
+		q.Offset = off
+	}
+	return q, nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// transcripts trata GET /api/transcripts (mensagens em ordem cronológica) e os
+// subcaminhos /contacts (conversas por número), /stats (métricas do período) e
+// /export (?format=csv ou jsonl, sem paginação).
+func (h *handler) transcripts(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+		return
+	}
+	q, err := transcriptQuery(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "data inválida: " + err.Error()})
+		return
+	}
+	st := transcript.Default()
+	ctx := r.Context()
+	switch strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/transcripts"), "/") {
+	case "":
+		msgs, err := st.List(ctx, q)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if msgs == nil {
+			msgs = []transcript.Message{}
+		}
+		writeJSON(w, http.StatusOK, msgs)
+	case "contacts":
+		list, err := st.Contacts(ctx, q)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		if list == nil {
+			list = []transcript.Contact{}
+		}
+		writeJSON(w, http.StatusOK, list)
+	case "stats":
+		stats, err := st.Stats(ctx, q)
+		if err != nil {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, stats)
+	case "export":
+		name := "transcricao-" + time.Now().UTC().Format("20060102-150405")
+		if strings.EqualFold(r.URL.Query().Get("format"), "jsonl") {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.jsonl"`)
+			err = transcript.WriteJSONL(ctx, w, st, q)
+		} else {
+			w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+			err = transcript.WriteCSV(ctx, w, st, q)
+		}
+		// cabeçalhos já enviados: só dá para registrar
+		if err != nil {
+			log.Println("transcript export:", err)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -22,6 +22,20 @@ func RegisterRoutes(mux *http.ServeMux, cfg config.Config) {
 	mux.HandleFunc("/webhook/uazapi", h.webhook)
 	// Webhook dinâmico: aceita /webhooks/<slug> e repassa ao handler
 	mux.HandleFunc("/webhooks/", h.webhookDynamic)
+	// Proxy de imagens de produto (HTTPS público para o carrossel)
+	mux.HandleFunc("/media/products/", h.mediaProduct)
+	// Campanhas ativas (autenticadas por AGENT_API_TOKEN)
+	mux.HandleFunc("/api/campaigns", h.requireToken(h.campaigns))
+	mux.HandleFunc("/api/campaigns/", h.requireToken(h.campaign))
+	// Opt-out e direitos do titular (LGPD)
+	mux.HandleFunc("/api/optout", h.requireToken(h.optOut))
+	mux.HandleFunc("/api/lgpd/export", h.requireToken(h.lgpdExport))
+	mux.HandleFunc("/api/lgpd/delete", h.requireToken(h.lgpdDelete))
+	// Fim do atendimento humano antes do prazo da pausa
+	mux.HandleFunc("/api/handoff/release", h.requireToken(h.handoffRelease))
+	// Transcrições locais: mensagens, conversas, métricas e exportação
+	mux.HandleFunc("/api/transcripts", h.requireToken(h.transcripts))
+	mux.HandleFunc("/api/transcripts/", h.requireToken(h.transcripts))
 }
 
 type handler struct {
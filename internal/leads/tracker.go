@@ -0,0 +1,210 @@
+// Package leads mantém o registro do lead no PacLead (:8889) atualizado a cada
+// mensagem, agrupando escritas para não inundar o /leadpost em conversas ativas.
+package leads
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	_ "time/tzdata" // garante America/Sao_Paulo em imagens sem zoneinfo
+
+	"pac-lead-agent/internal/state"
+	"pac-lead-agent/internal/types"
+)
+
+// Location é o fuso usado nas datas enviadas ao PacLead.
+var Location = mustLoad("America/Sao_Paulo")
+
+func mustLoad(name string) *time.Location {
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return loc
+}
+
+// Poster grava o lead (implementado por *clients.PacLead).
+type Poster interface {
+	LeadPost(ctx context.Context, lead types.LeadRecord) (map[string]any, error)
+}
+
+// Event é uma mensagem trocada com o lead.
+type Event struct {
+	Lead    types.LeadRecord // identidade atual (id, número, cnpj, thread, status)
+	Inbound bool
+	Name    string // pushName, quando inbound
+	Preview string
+	At      time.Time
+}
+
+// counters são os totais de mensagens por lead. Cada sentido é um contador
+// atômico no state.Store (statsKey), para que réplicas não percam incrementos.
+type counters struct {
+	In, Out int
+}
+
+// statsTTL descarta os contadores de leads parados; cada mensagem renova.
+const statsTTL = 365 * 24 * time.Hour
+
+func statsKey(key, dir string) string {
+	return "leadstats:" + key + ":" + dir
+}
+
+type pending struct {
+	rec    types.LeadRecord
+	poster Poster
+	timer  *time.Timer
+}
+
+// Tracker agrupa eventos por lead e grava no máximo uma vez por janela.
+type Tracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	store   state.Store
+	pending map[string]*pending
+}
+
+func NewTracker(window time.Duration, store state.Store) *Tracker {
+	return &Tracker{window: window, store: store, pending: map[string]*pending{}}
+}
+
+var (
+	defaultOnce    sync.Once
+	defaultTracker *Tracker
+)
+
+// Default devolve o Tracker do processo (janela de 10s, state.Default()).
+func Default() *Tracker {
+	defaultOnce.Do(func() { defaultTracker = NewTracker(10*time.Second, state.Default()) })
+	return defaultTracker
+}
+
+const previewLen = 120
+
+// Preview resume o texto para o campo de última mensagem.
+func Preview(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > previewLen {
+		s = string(r[:previewLen-1]) + "…"
+	}
+	return s
+}
+
+// Record registra o evento; a gravação acontece ao fim da janela.
+func (t *Tracker) Record(ctx context.Context, poster Poster, ev Event) {
+	if poster == nil || ev.Lead.Numero == "" {
+		return
+	}
+	if ev.At.IsZero() {
+		ev.At = time.Now()
+	}
+	key := ev.Lead.CNPJCPF + ":" + ev.Lead.Numero
+	c := t.bump(ctx, key, ev.Inbound)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.pending[key]
+	if !ok {
+		p = &pending{rec: ev.Lead}
+		t.pending[key] = p
+		p.timer = time.AfterFunc(t.window, func() { t.flush(key) })
+	}
+	p.poster = poster
+	rec := &p.rec
+	// identidade mais recente prevalece (ex.: thread recém-criada)
+	if ev.Lead.ID != 0 {
+		rec.ID = ev.Lead.ID
+	}
+	if ev.Lead.ThreadID != "" {
+		rec.ThreadID = ev.Lead.ThreadID
+	}
+	if ev.Inbound && strings.TrimSpace(ev.Name) != "" {
+		rec.Nome = strings.TrimSpace(ev.Name)
+	}
+	rec.DataUltMsg = ev.At.In(Location).Format("2006-01-02 15:04")
+	if ev.Inbound {
+		rec.UltMsgNum = Preview(ev.Preview)
+	} else {
+		rec.UltMsgBot = Preview(ev.Preview)
+	}
+	rec.MsgRecebidas, rec.MsgEnviadas = c.In, c.Out
+}
+
+// bump incrementa atomicamente o contador do sentido da mensagem e devolve
+// os dois totais.
+func (t *Tracker) bump(ctx context.Context, key string, inbound bool) counters {
+	if t.store == nil {
+		return counters{}
+	}
+	dir := "out"
+	if inbound {
+		dir = "in"
+	}
+	if _, err := t.store.Incr(ctx, statsKey(key, dir), 1, statsTTL); err != nil {
+		log.Println("leadstats:", err)
+	}
+	return t.stats(ctx, key)
+}
+
+// stats lê os dois contadores do lead.
+func (t *Tracker) stats(ctx context.Context, key string) counters {
+	var in, out int64
+	_, _ = t.store.Get(ctx, statsKey(key, "in"), &in)
+	_, _ = t.store.Get(ctx, statsKey(key, "out"), &out)
+	return counters{In: int(in), Out: int(out)}
+}
+
+// Stats devolve os contadores persistidos do lead (exportação de dados).
+func (t *Tracker) Stats(ctx context.Context, cnpj, number string) (in, out int) {
+	if t.store == nil {
+		return 0, 0
+	}
+	c := t.stats(ctx, cnpj+":"+number)
+	return c.In, c.Out
+}
+
+// Forget descarta a gravação pendente e os contadores do lead (exclusão de dados).
+func (t *Tracker) Forget(ctx context.Context, cnpj, number string) {
+	key := cnpj + ":" + number
+	t.mu.Lock()
+	if p, ok := t.pending[key]; ok {
+		p.timer.Stop()
+		delete(t.pending, key)
+	}
+	t.mu.Unlock()
+	if t.store != nil {
+		_ = t.store.Delete(ctx, statsKey(key, "in"))
+		_ = t.store.Delete(ctx, statsKey(key, "out"))
+	}
+}
+
+func (t *Tracker) flush(key string) {
+	t.mu.Lock()
+	p, ok := t.pending[key]
+	delete(t.pending, key)
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if _, err := p.poster.LeadPost(ctx, p.rec); err != nil {
+		log.Println("lead update:", err, "numero:", p.rec.Numero)
+	}
+}
+
+// Flush grava imediatamente tudo o que estiver pendente (uso no shutdown).
+func (t *Tracker) Flush() {
+	t.mu.Lock()
+	keys := make([]string, 0, len(t.pending))
+	for k, p := range t.pending {
+		p.timer.Stop()
+		keys = append(keys, k)
+	}
+	t.mu.Unlock()
+	for _, k := range keys {
+		t.flush(k)
+	}
+}
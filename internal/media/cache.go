@@ -0,0 +1,69 @@
+package media
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache guarda em memória imagens já normalizadas, com TTL e limite de itens.
+type Cache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	data    []byte
+	ctype   string
+	expires time.Time
+}
+
+func NewCache(ttl time.Duration, maxEntries int) *Cache {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	return &Cache{ttl: ttl, max: maxEntries, entries: map[string]cacheEntry{}}
+}
+
+func (c *Cache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, "", false
+	}
+	if time.Now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, "", false
+	}
+	return e.data, e.ctype, true
+}
+
+func (c *Cache) Set(key string, data []byte, ctype string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.max {
+		c.evict()
+	}
+	c.entries[key] = cacheEntry{data: data, ctype: ctype, expires: time.Now().Add(c.ttl)}
+}
+
+// evict remove expirados e, se ainda cheio, o item que expira primeiro.
+func (c *Cache) evict() {
+	now := time.Now()
+	var oldestKey string
+	var oldest time.Time
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+			continue
+		}
+		if oldestKey == "" || e.expires.Before(oldest) {
+			oldestKey, oldest = k, e.expires
+		}
+	}
+	if len(c.entries) >= c.max && oldestKey != "" {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,136 @@
+// Package media normaliza imagens de produto para envio no WhatsApp.
+package media
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"net/http"
+	"strings"
+
+	// Registra decoders usados pelo catálogo
+	_ "image/gif"
+	_ "image/png"
+)
+
+const (
+	// MaxDimension é o maior lado aceito; o WhatsApp recomprime acima disso.
+	MaxDimension = 1024
+	// MaxBytes é o limite prático de imagens no WhatsApp (5 MB).
+	MaxBytes = 5 << 20
+	// MaxPixels limita a área decodificada: um PNG de poucos KB pode declarar
+	// dimensões que custariam gigabytes de memória (40 MP ≈ 160 MB em RGBA).
+	MaxPixels = 40_000_000
+)
+
+var (
+	// ErrNotImage indica que o conteúdo não é uma imagem reconhecida.
+	ErrNotImage = errors.New("media: content is not an image")
+	// ErrTooManyPixels indica uma imagem acima de MaxPixels.
+	ErrTooManyPixels = errors.New("media: image exceeds pixel budget")
+)
+
+// DetectContentType identifica o tipo do conteúdo, incluindo WEBP.
+func DetectContentType(data []byte) string {
+	ct := http.DetectContentType(data)
+	if i := strings.IndexByte(ct, ';'); i > 0 {
+		ct = ct[:i]
+	}
+	return ct
+}
+
+// Normalize converte a imagem para JPEG, reduzindo para MaxDimension e para
+// menos de MaxBytes. Formatos que a stdlib não decodifica (ex.: WEBP) são
+// devolvidos como estão, desde que caibam no limite. As dimensões são lidas do
+// cabeçalho antes de decodificar; acima de MaxPixels a imagem é recusada.
+func Normalize(data []byte) ([]byte, string, error) {
+	ct := DetectContentType(data)
+	if !strings.HasPrefix(ct, "image/") {
+		return nil, "", ErrNotImage
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err == nil && (cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > MaxPixels) {
+		return nil, "", ErrTooManyPixels
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		if len(data) <= MaxBytes {
+			return data, ct, nil
+		}
+		return nil, "", err
+	}
+	// JPEG dentro dos limites: não recomprime
+	b := img.Bounds()
+	if ct == "image/jpeg" && b.Dx() <= MaxDimension && b.Dy() <= MaxDimension && len(data) <= MaxBytes {
+		return data, ct, nil
+	}
+	img = flatten(resize(img, MaxDimension))
+	for _, q := range []int{85, 70, 55} {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: q}); err != nil {
+			return nil, "", err
+		}
+		if buf.Len() <= MaxBytes {
+			return buf.Bytes(), "image/jpeg", nil
+		}
+	}
+	return nil, "", errors.New("media: image too large after compression")
+}
+
+// resize reduz a imagem (amostragem por área) mantendo a proporção.
+func resize(src image.Image, max int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= max && h <= max {
+		return src
+	}
+	nw, nh := max, h*max/w
+	if h > w {
+		nw, nh = w*max/h, max
+	}
+	if nw < 1 {
+		nw = 1
+	}
+	if nh < 1 {
+		nh = 1
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, nw, nh))
+	for y := 0; y < nh; y++ {
+		y0 := b.Min.Y + y*h/nh
+		y1 := b.Min.Y + (y+1)*h/nh
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+		for x := 0; x < nw; x++ {
+			x0 := b.Min.X + x*w/nw
+			x1 := b.Min.X + (x+1)*w/nw
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+			var r, g, bl, a, n uint32
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, bl, a = r+cr, g+cg, bl+cb, a+ca
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n), G: uint16(g / n), B: uint16(bl / n), A: uint16(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// flatten compõe a imagem sobre fundo branco (JPEG não tem transparência).
+func flatten(src image.Image) image.Image {
+	b := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
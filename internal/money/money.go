@@ -0,0 +1,192 @@
+// Package money interpreta e formata valores em reais (BRL) no padrão brasileiro.
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Amount é um valor em centavos.
+type Amount int64
+
+// Unavailable é o texto exibido quando o preço não pode ser interpretado.
+const Unavailable = "indisponível"
+
+// Parse interpreta um preço em reais vindo do PacLead: float64 (1299.9),
+// json.Number, ou string ("1299.90", "1.299,90", "R$ 1.299,90").
+func Parse(v any) (Amount, bool) {
+	switch x := v.(type) {
+	case nil:
+		return 0, false
+	case float64:
+		if math.IsNaN(x) || math.IsInf(x, 0) {
+			return 0, false
+		}
+		return Amount(math.Round(x * 100)), true
+	case float32:
+		return Parse(float64(x))
+	case int:
+		return Amount(x) * 100, true
+	case int64:
+		return Amount(x) * 100, true
+	case json.Number:
+		return ParseString(x.String())
+	case string:
+		return ParseString(x)
+	}
+	return 0, false
+}
+
+// ParseCents interpreta campos já em centavos (ex.: preco_centavos: 129990).
+func ParseCents(v any) (Amount, bool) {
+	switch x := v.(type) {
+	case float64:
+		return Amount(math.Round(x)), true
+	case int:
+		return Amount(x), true
+	case int64:
+		return Amount(x), true
+	case json.Number:
+		n, err := x.Int64()
+		return Amount(n), err == nil
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64)
+		return Amount(n), err == nil
+	}
+	return 0, false
+}
+
+// ParseString aceita separadores nos formatos brasileiro e americano. Quando há
+// só um tipo de separador, ele é decimal se seguido de 1 ou 2 dígitos.
+func ParseString(s string) (Amount, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "R$"), "r$")
+	s = strings.TrimSpace(s)
+	neg := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	if s == "" {
+		return 0, false
+	}
+	lastDot := strings.LastIndexByte(s, '.')
+	lastComma := strings.LastIndexByte(s, ',')
+	dec := -1
+	switch {
+	case lastDot >= 0 && lastComma >= 0:
+		if lastDot > lastComma {
+			dec = lastDot
+		} else {
+			dec = lastComma
+		}
+	case lastComma >= 0:
+		if n := len(s) - lastComma - 1; n <= 2 && strings.Count(s, ",") == 1 {
+			dec = lastComma
+		}
+	case lastDot >= 0:
+		if n := len(s) - lastDot - 1; n <= 2 && strings.Count(s, ".") == 1 {
+			dec = lastDot
+		}
+	}
+	intPart, fracPart := s, ""
+	if dec >= 0 {
+		intPart, fracPart = s[:dec], s[dec+1:]
+	}
+	intPart = strings.NewReplacer(".", "", ",", "", " ", "").Replace(intPart)
+	if intPart == "" {
+		intPart = "0"
+	}
+	if !digits(intPart) || !digits(fracPart) {
+		return 0, false
+	}
+	for len(fracPart) < 2 {
+		fracPart += "0"
+	}
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	c, _ := strconv.ParseInt(fracPart[:2], 10, 64)
+	a := Amount(n*100 + c)
+	if neg {
+		a = -a
+	}
+	return a, true
+}
+
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Reais devolve o valor em reais (para payloads que esperam float).
+func (a Amount) Reais() float64 { return float64(a) / 100 }
+
+// String formata como "R$ 1.299,90".
+func (a Amount) String() string { return "R$ " + a.Number() }
+
+// Number formata sem símbolo: "1.299,90".
+func (a Amount) Number() string {
+	neg := a < 0
+	if neg {
+		a = -a
+	}
+	ints := strconv.FormatInt(int64(a/100), 10)
+	var b strings.Builder
+	if neg {
+		b.WriteByte('-')
+	}
+	for i, r := range ints {
+		if i > 0 && (len(ints)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte(',')
+	cents := int64(a % 100)
+	if cents < 10 {
+		b.WriteByte('0')
+	}
+	b.WriteString(strconv.FormatInt(cents, 10))
+	return b.String()
+}
+
+// Format interpreta e formata em um passo; devolve "indisponível" se inválido.
+func Format(v any) string {
+	a, ok := Parse(v)
+	if !ok || a <= 0 {
+		return Unavailable
+	}
+	return a.String()
+}
+
+// Installment divide o total em n parcelas: "10x de R$ 129,99".
+// A parcela é arredondada para baixo; a diferença fica implícita na primeira.
+func Installment(total Amount, n int) string {
+	if n <= 1 || total <= 0 {
+		return ""
+	}
+	return strconv.Itoa(n) + "x de " + (total / Amount(n)).String()
+}
+
+// PriceLine monta a linha de preço de um card: com desconto quando original > price
+// ("De R$ 1.499,90 por R$ 1.299,90") e parcelamento opcional.
+func PriceLine(price, original Amount, installments int) string {
+	if price <= 0 {
+		return "Preço: " + Unavailable
+	}
+	var b strings.Builder
+	if original > price {
+		b.WriteString("De ~" + original.String() + "~ por *" + price.String() + "*")
+	} else {
+		b.WriteString("Preço: *" + price.String() + "*")
+	}
+	if s := Installment(price, installments); s != "" {
+		b.WriteString("\nou " + s)
+	}
+	return b.String()
+}
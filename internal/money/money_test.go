@@ -0,0 +1,177 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseString(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Amount
+		ok   bool
+	}{
+		{"1299.90", 129990, true},
+		{"1.299,90", 129990, true},
+		{"R$ 1.299,90", 129990, true},
+		{"r$1299,9", 129990, true},
+		{"1,299.90", 129990, true},
+		{"1.234.567,89", 123456789, true},
+		{"1.299", 129900, true}, // ponto seguido de 3 dígitos é milhar
+		{"1,299", 129900, true},
+		{"12.5", 1250, true},
+		{"1,5", 150, true},
+		{"0,99", 99, true},
+		{",50", 50, true},
+		{"10", 1000, true},
+		{" 10 ", 1000, true},
+		{"-5,00", -500, true},
+		{"", 0, false},
+		{"R$", 0, false},
+		{"abc", 0, false},
+		{"12,3x", 0, false},
+	}
+	for _, c := range cases {
+		got, ok := ParseString(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("ParseString(%q) = %d, %v; want %d, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name string
+		in   any
+		want Amount
+		ok   bool
+	}{
+		{"float", 1299.9, 129990, true},
+		{"float arredonda", 19.99, 1999, true},
+		{"float32", float32(2.5), 250, true},
+		{"int", 5, 500, true},
+		{"int64", int64(7), 700, true},
+		{"json.Number", json.Number("12.50"), 1250, true},
+		{"string", "R$ 49,90", 4990, true},
+		{"nil", nil, 0, false},
+		{"NaN", math.NaN(), 0, false},
+		{"Inf", math.Inf(1), 0, false},
+		{"bool", true, 0, false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := Parse(c.in)
+			if got != c.want || ok != c.ok {
+				t.Errorf("Parse(%v) = %d, %v; want %d, %v", c.in, got, ok, c.want, c.ok)
+			}
+		})
+	}
+}
+
+func TestParseCents(t *testing.T) {
+	cases := []struct {
+		in   any
+		want Amount
+		ok   bool
+	}{
+		{129990.0, 129990, true},
+		{129990, 129990, true},
+		{int64(1), 1, true},
+		{json.Number("4990"), 4990, true},
+		{json.Number("49.90"), 0, false},
+		{" 4990 ", 4990, true},
+		{"49,90", 0, false},
+		{nil, 0, false},
+	}
+	for _, c := range cases {
+		got, ok := ParseCents(c.in)
+		if got != c.want || ok != c.ok {
+			t.Errorf("ParseCents(%v) = %d, %v; want %d, %v", c.in, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestNumber(t *testing.T) {
+	cases := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0,00"},
+		{5, "0,05"},
+		{99, "0,99"},
+		{100, "1,00"},
+		{99999, "999,99"},
+		{129990, "1.299,90"},
+		{100000000, "1.000.000,00"},
+		{-129990, "-1.299,90"},
+	}
+	for _, c := range cases {
+		if got := c.in.Number(); got != c.want {
+			t.Errorf("Amount(%d).Number() = %q, want %q", c.in, got, c.want)
+		}
+	}
+	if got := Amount(129990).String(); got != "R$ 1.299,90" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	cases := []struct {
+		in   any
+		want string
+	}{
+		{1299.9, "R$ 1.299,90"},
+		{"1.299,90", "R$ 1.299,90"},
+		{0, Unavailable},
+		{-10, Unavailable},
+		{"abc", Unavailable},
+		{nil, Unavailable},
+	}
+	for _, c := range cases {
+		if got := Format(c.in); got != c.want {
+			t.Errorf("Format(%v) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestInstallment(t *testing.T) {
+	cases := []struct {
+		total Amount
+		n     int
+		want  string
+	}{
+		{129990, 10, "10x de R$ 129,99"},
+		{100, 3, "3x de R$ 0,33"}, // arredonda para baixo
+		{129990, 1, ""},
+		{129990, 0, ""},
+		{0, 10, ""},
+	}
+	for _, c := range cases {
+		if got := Installment(c.total, c.n); got != c.want {
+			t.Errorf("Installment(%d, %d) = %q, want %q", c.total, c.n, got, c.want)
+		}
+	}
+}
+
+func TestPriceLine(t *testing.T) {
+	cases := []struct {
+		name            string
+		price, original Amount
+		installments    int
+		want            string
+	}{
+		{"simples", 1000, 0, 0, "Preço: *R$ 10,00*"},
+		{"desconto", 129990, 149990, 0, "De ~R$ 1.499,90~ por *R$ 1.299,90*"},
+		{"desconto e parcelas", 129990, 149990, 10, "De ~R$ 1.499,90~ por *R$ 1.299,90*\nou 10x de R$ 129,99"},
+		{"original menor ignorado", 1000, 900, 0, "Preço: *R$ 10,00*"},
+		{"sem preço", 0, 1000, 3, "Preço: " + Unavailable},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := PriceLine(c.price, c.original, c.installments); got != c.want {
+				t.Errorf("PriceLine = %q, want %q", got, c.want)
+			}
+		})
+	}
+}
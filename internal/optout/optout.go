@@ -0,0 +1,105 @@
+// Package optout mantém, por tenant, os números que pediram para não receber
+// mais mensagens ("SAIR", "PARE"...). Dispatcher, follow-ups e campanhas
+// consultam o registro antes de enviar.
+package optout
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Record é o registro do pedido (mantido mesmo após exclusão de dados, para
+// continuar respeitando o pedido).
+type Record struct {
+	At     time.Time `json:"at"`
+	Reason string    `json:"reason,omitempty"`
+	// Source: "message" (pedido do lead), "admin" (API) ...
+	Source string `json:"source"`
+}
+
+// Registry guarda os registros no state.Store, sem expiração.
+type Registry struct {
+	st state.Store
+}
+
+func New(st state.Store) *Registry { return &Registry{st: st} }
+
+// Default usa o state.Store do processo.
+func Default() *Registry { return New(state.Default()) }
+
+func key(cnpj, number string) string {
+	return "optout:" + cnpj + ":" + number
+}
+
+func (r *Registry) Add(ctx context.Context, cnpj, number string, rec Record) error {
+	if rec.At.IsZero() {
+		rec.At = time.Now().UTC()
+	}
+	return r.st.Set(ctx, key(cnpj, number), rec, 0)
+}
+
+func (r *Registry) Remove(ctx context.Context, cnpj, number string) error {
+	return r.st.Delete(ctx, key(cnpj, number))
+}
+
+// Get devolve o registro, se o número pediu opt-out.
+func (r *Registry) Get(ctx context.Context, cnpj, number string) (Record, bool) {
+	var rec Record
+	ok, err := r.st.Get(ctx, key(cnpj, number), &rec)
+	return rec, ok && err == nil
+}
+
+// Is informa se o número pediu para não receber mensagens.
+func (r *Registry) Is(ctx context.Context, cnpj, number string) bool {
+	_, ok := r.Get(ctx, cnpj, number)
+	return ok
+}
+
+var (
+	// "cancelar" e "remover" ficam de fora: sozinhos costumam se referir a
+	// pedido ou item do carrinho, não às mensagens
+	stopWords = map[string]bool{
+		"sair": true, "pare": true, "parar": true, "stop": true,
+		"descadastrar": true, "descadastre": true, "unsubscribe": true,
+	}
+	stopPhrases = []string{
+		"nao quero mais mensage", "nao quero mais receber", "nao me mande mais", "nao me envie mais",
+		"pare de me mandar", "pare de mandar", "pare de enviar", "me tire da lista", "me remova da lista",
+		"nao tenho interesse em receber", "cancelar inscricao", "cancelar as mensagens", "remover meu numero",
+	}
+	startWords = map[string]bool{"voltar": true, "start": true, "quero receber": true, "receber mensagens": true}
+)
+
+// IsStop reconhece um pedido de opt-out: palavra isolada ("SAIR", "PARE") ou
+// frase explícita ("não quero mais mensagens").
+func IsStop(text string) bool {
+	t := normalize(text)
+	if stopWords[t] {
+		return true
+	}
+	for _, p := range stopPhrases {
+		if strings.Contains(t, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsStart reconhece o pedido para voltar a receber mensagens ("VOLTAR").
+func IsStart(text string) bool {
+	return startWords[normalize(text)]
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "é", "e", "ê", "e", "í", "i",
+	"ó", "o", "ô", "o", "õ", "o", "ú", "u", "ü", "u", "ç", "c",
+)
+
+func normalize(s string) string {
+	s = accents.Replace(strings.ToLower(strings.TrimSpace(s)))
+	s = strings.Trim(s, ".!?,;: ")
+	return strings.Join(strings.Fields(s), " ")
+}
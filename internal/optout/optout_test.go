@@ -0,0 +1,81 @@
+package optout
+
+import (
+	"context"
+	"testing"
+
+	"pac-lead-agent/internal/state"
+)
+
+func TestIsStop(t *testing.T) {
+	cases := []struct {
+		in   string
+		want bool
+	}{
+		{"SAIR", true},
+		{"  Pare! ", true},
+		{"parar", true},
+		{"Stop.", true},
+		{"cancelar", false}, // sozinho costuma ser sobre o pedido
+		{"remover", false},
+		{"Cancelar inscrição", true},
+		{"quero cancelar as mensagens", true},
+		{"pode remover meu número", true},
+		{"Não quero mais mensagens", true},
+		{"por favor, NÃO ME MANDE MAIS nada", true},
+		{"me tire da lista", true},
+		{"Pare   de   enviar", true},
+		{"quero sair do grupo de promoções", false},
+		{"qual o horário de saída?", false},
+		{"não quero mais o anel", false},
+		{"quero cancelar o pedido", false},
+		{"remover o colar do carrinho", false},
+		{"", false},
+	}
+	for _, c := range cases {
+		if got := IsStop(c.in); got != c.want {
+			t.Errorf("IsStop(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestIsStart(t *testing.T) {
+	cases := []struct {
+		in   string
+		want bool
+	}{
+		{"VOLTAR", true},
+		{"Quero receber!", true},
+		{"start", true},
+		{"quero voltar a comprar", false},
+	}
+	for _, c := range cases {
+		if got := IsStart(c.in); got != c.want {
+			t.Errorf("IsStart(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	r := New(state.NewMemory())
+	if r.Is(ctx, "123", "5511999998888") {
+		t.Fatal("número novo já consta como opt-out")
+	}
+	if err := r.Add(ctx, "123", "5511999998888", Record{Source: "message", Reason: "SAIR"}); err != nil {
+		t.Fatal(err)
+	}
+	rec, ok := r.Get(ctx, "123", "5511999998888")
+	if !ok || rec.Source != "message" || rec.At.IsZero() {
+		t.Errorf("Get = %+v, %v", rec, ok)
+	}
+	if r.Is(ctx, "456", "5511999998888") {
+		t.Error("opt-out vazou para outro tenant")
+	}
+	if err := r.Remove(ctx, "123", "5511999998888"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Is(ctx, "123", "5511999998888") {
+		t.Error("opt-out continua após Remove")
+	}
+}
@@ -0,0 +1,141 @@
+// Package phone interpreta JIDs do WhatsApp e normaliza números brasileiros
+// para E.164, resolvendo o nono dígito, para que o mesmo cliente tenha uma
+// única chave (lead, buffer, lock) independente de como o gateway o envia.
+package phone
+
+import (
+	"strings"
+)
+
+// Kind é o tipo de JID.
+type Kind int
+
+const (
+	User  Kind = iota // @s.whatsapp.net / @c.us / número puro
+	Group             // @g.us
+	LID               // @lid (identificador oculto, não é telefone)
+)
+
+// JID é um endereço do WhatsApp.
+type JID struct {
+	User   string // parte antes do @ (sem sufixo de dispositivo)
+	Server string
+	Kind   Kind
+}
+
+// ParseJID aceita "5511999999999@s.whatsapp.net", "5511999999999:12@s.whatsapp.net",
+// "5511999999999@c.us", "1203...@g.us", "1234@lid" ou apenas o número.
+func ParseJID(s string) JID {
+	s = strings.TrimSpace(s)
+	user, server := s, ""
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		user, server = s[:i], strings.ToLower(s[i+1:])
+	}
+	// sufixo de dispositivo (multi-device): "5511...:12"
+	if i := strings.IndexByte(user, ':'); i >= 0 {
+		user = user[:i]
+	}
+	j := JID{User: user, Server: server, Kind: User}
+	switch server {
+	case "g.us":
+		j.Kind = Group
+	case "lid":
+		j.Kind = LID
+	}
+	return j
+}
+
+// Key é a chave canônica do contato: E.164 sem "+" para telefones e o JID
+// completo para grupos e LIDs.
+func (j JID) Key() string {
+	switch j.Kind {
+	case Group, LID:
+		return j.User + "@" + j.Server
+	}
+	if j.Server != "" {
+		// JIDs de usuário sempre trazem o DDI
+		return canonical(digits(j.User), true)
+	}
+	return Canonical(j.User)
+}
+
+// Canonical devolve os dígitos E.164 (sem "+") de um número; números
+// brasileiros ganham DDI 55 e nono dígito quando faltarem. Números com "+"
+// são considerados já com DDI.
+func Canonical(number string) string {
+	d := digits(number)
+	if d == "" {
+		return strings.TrimSpace(number)
+	}
+	return canonical(d, strings.HasPrefix(strings.TrimSpace(number), "+"))
+}
+
+func canonical(d string, hasDDI bool) string {
+	if !hasDDI {
+		d = strings.TrimLeft(d, "0")
+		switch len(d) {
+		case 10, 11: // DDD + número, sem DDI
+			if validDDD(d[:2]) {
+				d = "55" + d
+			}
+		}
+	}
+	if strings.HasPrefix(d, "55") && (len(d) == 12 || len(d) == 13) {
+		ddd, local := d[2:4], d[4:]
+		// celular antigo sem o nono dígito: 8 dígitos começando com 6-9
+		if len(local) == 8 && local[0] >= '6' {
+			local = "9" + local
+		}
+		d = "55" + ddd + local
+	}
+	return d
+}
+
+// E164 formata como "+5511999999999".
+func E164(number string) string {
+	c := Canonical(number)
+	if c == "" || !isDigits(c) {
+		return c
+	}
+	return "+" + c
+}
+
+// Variants devolve a chave canônica e a forma antiga sem o nono dígito, para
+// buscar registros gravados antes da normalização.
+func Variants(key string) []string {
+	out := []string{key}
+	if strings.HasPrefix(key, "55") && len(key) == 13 && key[4] == '9' && key[5] >= '6' {
+		out = append(out, key[:4]+key[5:])
+	}
+	return out
+}
+
+// IsBrazilianMobile informa se o número canônico é celular brasileiro.
+func IsBrazilianMobile(number string) bool {
+	c := Canonical(number)
+	return strings.HasPrefix(c, "55") && len(c) == 13 && c[4] == '9'
+}
+
+func digits(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// validDDD cobre os códigos de área brasileiros (11..99, sem dígito 0).
+func validDDD(d string) bool {
+	return len(d) == 2 && d[0] >= '1' && d[0] <= '9' && d[1] >= '1' && d[1] <= '9'
+}
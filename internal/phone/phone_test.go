@@ -0,0 +1,111 @@
+package phone
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"celular com DDD", "11 99999-8888", "5511999998888"},
+		{"celular com DDI", "+55 (11) 99999-8888", "5511999998888"},
+		{"DDI sem +", "5511999998888", "5511999998888"},
+		{"zero do DDD", "011 99999-8888", "5511999998888"},
+		{"sem nono dígito", "(11) 9999-8888", "5511999998888"},
+		{"sem nono dígito com DDI", "+55 11 8888-7777", "5511988887777"},
+		{"DDI sem nono dígito", "551176665555", "5511976665555"},
+		{"fixo", "(11) 3333-4444", "551133334444"},
+		{"fixo com DDI", "+55 21 2555-1234", "552125551234"},
+		{"estrangeiro", "+1 415 555 2671", "14155552671"},
+		{"curto demais para ter DDD", "0199998888", "199998888"},
+		{"vazio", "", ""},
+		{"sem dígitos", " abc ", "abc"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Canonical(c.in); got != c.want {
+				t.Errorf("Canonical(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
+
+func TestParseJID(t *testing.T) {
+	cases := []struct {
+		in   string
+		want JID
+		key  string
+	}{
+		{"5511999998888@s.whatsapp.net", JID{"5511999998888", "s.whatsapp.net", User}, "5511999998888"},
+		{"551199998888@s.whatsapp.net", JID{"551199998888", "s.whatsapp.net", User}, "5511999998888"},
+		{"5511999998888:12@s.whatsapp.net", JID{"5511999998888", "s.whatsapp.net", User}, "5511999998888"},
+		{"551199998888@c.us", JID{"551199998888", "c.us", User}, "5511999998888"},
+		{"5511999998888@S.WhatsApp.Net", JID{"5511999998888", "s.whatsapp.net", User}, "5511999998888"},
+		// JID de usuário sempre tem DDI: não ganha 55
+		{"14155552671@s.whatsapp.net", JID{"14155552671", "s.whatsapp.net", User}, "14155552671"},
+		{"120363025246125486@g.us", JID{"120363025246125486", "g.us", Group}, "120363025246125486@g.us"},
+		{"98765432101234@lid", JID{"98765432101234", "lid", LID}, "98765432101234@lid"},
+		{" 11 99999-8888 ", JID{"11 99999-8888", "", User}, "5511999998888"},
+	}
+	for _, c := range cases {
+		j := ParseJID(c.in)
+		if j != c.want {
+			t.Errorf("ParseJID(%q) = %+v, want %+v", c.in, j, c.want)
+		}
+		if got := j.Key(); got != c.key {
+			t.Errorf("ParseJID(%q).Key() = %q, want %q", c.in, got, c.key)
+		}
+	}
+}
+
+func TestVariants(t *testing.T) {
+	cases := []struct {
+		in   string
+		want []string
+	}{
+		{"5511999998888", []string{"5511999998888", "551199998888"}},
+		{"5511988887777", []string{"5511988887777", "551188887777"}},
+		{"551133334444", []string{"551133334444"}},
+		{"5511912345678", []string{"5511912345678"}}, // 9 seguido de 1: não é o nono dígito
+		{"14155552671", []string{"14155552671"}},
+	}
+	for _, c := range cases {
+		if got := Variants(c.in); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Variants(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestE164(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"11 99999-8888", "+5511999998888"},
+		{"(11) 9999-8888", "+5511999998888"},
+		{"+1 415 555 2671", "+14155552671"},
+		{"", ""},
+		{"abc", "abc"},
+	}
+	for _, c := range cases {
+		if got := E164(c.in); got != c.want {
+			t.Errorf("E164(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+func TestIsBrazilianMobile(t *testing.T) {
+	cases := []struct {
+		in   string
+		want bool
+	}{
+		{"11 99999-8888", true},
+		{"(11) 9999-8888", true},
+		{"(11) 3333-4444", false},
+		{"+1 415 555 2671", false},
+	}
+	for _, c := range cases {
+		if got := IsBrazilianMobile(c.in); got != c.want {
+			t.Errorf("IsBrazilianMobile(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package pix
+
+// CRC16 calcula o CRC-16/CCITT-FALSE (polinômio 0x1021, inicial 0xFFFF) exigido no campo 63.
+func CRC16(s string) uint16 {
+	crc := uint16(0xFFFF)
+	for i := 0; i < len(s); i++ {
+		crc ^= uint16(s[i]) << 8
+		for j := 0; j < 8; j++ {
+			if crc&0x8000 != 0 {
+				crc = crc<<1 ^ 0x1021
+			} else {
+				crc <<= 1
+			}
+		}
+	}
+	return crc
+}
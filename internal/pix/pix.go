@@ -0,0 +1,130 @@
+// Package pix gera BR Codes Pix (EMV QRCPS-MPM, "copia e cola") estáticos e
+// dinâmicos, conforme o Manual de Padrões para Iniciação do Pix do BCB.
+package pix
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"pac-lead-agent/internal/money"
+)
+
+const gui = "br.gov.bcb.pix"
+
+// Payload descreve a cobrança. Sem URL o código é estático (chave Pix); com URL
+// (location do PSP, sem "https://") é dinâmico e de uso único.
+type Payload struct {
+	Key          string
+	MerchantName string
+	MerchantCity string
+	Amount       money.Amount
+	// TxID identifica a cobrança no extrato (até 25 alfanuméricos); vazio = "***".
+	TxID        string
+	Description string
+	URL         string
+}
+
+// BRCode monta o código copia-e-cola com CRC16 ao final.
+func (p Payload) BRCode() (string, error) {
+	name := sanitize(p.MerchantName, 25)
+	city := sanitize(p.MerchantCity, 15)
+	if name == "" || city == "" {
+		return "", errors.New("pix: merchant name and city are required")
+	}
+	dynamic := strings.TrimSpace(p.URL) != ""
+	key := strings.TrimSpace(p.Key)
+	if !dynamic && key == "" {
+		return "", errors.New("pix: key is required for static codes")
+	}
+
+	var mai strings.Builder
+	mai.WriteString(field("00", gui))
+	if dynamic {
+		u := strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(p.URL), "https://"), "http://")
+		mai.WriteString(field("25", u))
+	} else {
+		mai.WriteString(field("01", key))
+		if d := sanitize(p.Description, 99); d != "" && mai.Len()+len(d)+4 <= 99 {
+			mai.WriteString(field("02", d))
+		}
+	}
+	if mai.Len() > 99 {
+		return "", errors.New("pix: merchant account information too long")
+	}
+
+	var b strings.Builder
+	b.WriteString(field("00", "01"))
+	if dynamic {
+		b.WriteString(field("01", "12")) // uso único
+	}
+	b.WriteString(field("26", mai.String()))
+	b.WriteString(field("52", "0000"))
+	b.WriteString(field("53", "986"))
+	if p.Amount > 0 {
+		b.WriteString(field("54", fmt.Sprintf("%d.%02d", p.Amount/100, p.Amount%100)))
+	}
+	b.WriteString(field("58", "BR"))
+	b.WriteString(field("59", name))
+	b.WriteString(field("60", city))
+	txid := txID(p.TxID)
+	if dynamic {
+		txid = "***"
+	}
+	b.WriteString(field("62", field("05", txid)))
+	b.WriteString("6304")
+	return b.String() + fmt.Sprintf("%04X", CRC16(b.String())), nil
+}
+
+// field codifica um campo EMV: ID (2) + tamanho (2) + valor.
+func field(id, value string) string {
+	return fmt.Sprintf("%s%02d%s", id, len(value), value)
+}
+
+// txID mantém só alfanuméricos (até 25); vazio vira "***".
+func txID(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+		if b.Len() == 25 {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return "***"
+	}
+	return b.String()
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+	"Á", "A", "À", "A", "Â", "A", "Ã", "A", "Ä", "A",
+	"É", "E", "È", "E", "Ê", "E", "Ë", "E",
+	"Í", "I", "Ì", "I", "Î", "I", "Ï", "I",
+	"Ó", "O", "Ò", "O", "Ô", "O", "Õ", "O", "Ö", "O",
+	"Ú", "U", "Ù", "U", "Û", "U", "Ü", "U",
+	"Ç", "C", "Ñ", "N",
+)
+
+// sanitize remove acentos e caracteres fora do ASCII imprimível e limita o tamanho.
+func sanitize(s string, max int) string {
+	s = accents.Replace(strings.TrimSpace(s))
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7f {
+			b.WriteRune(r)
+		}
+	}
+	out := strings.TrimSpace(b.String())
+	if len(out) > max {
+		out = strings.TrimSpace(out[:max])
+	}
+	return out
+}
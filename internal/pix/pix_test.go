@@ -0,0 +1,164 @@
+package pix
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestCRC16(t *testing.T) {
+	cases := []struct {
+		in   string
+		want uint16
+	}{
+		{"", 0xFFFF},
+		{"123456789", 0x29B1}, // valor de verificação do CRC-16/CCITT-FALSE
+		{"A", 0xB915},
+		// exemplo de BR Code estático do Manual de Padrões do BCB (até o "6304")
+		{"00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***6304", 0x1D3D},
+	}
+	for _, c := range cases {
+		if got := CRC16(c.in); got != c.want {
+			t.Errorf("CRC16(%q) = %04X, want %04X", c.in, got, c.want)
+		}
+	}
+}
+
+func TestBRCodeManualExample(t *testing.T) {
+	p := Payload{
+		Key:          "123e4567-e12b-12d1-a456-426655440000",
+		MerchantName: "Fulano de Tal",
+		MerchantCity: "BRASILIA",
+	}
+	want := "00020126580014br.gov.bcb.pix0136123e4567-e12b-12d1-a456-4266554400005204000053039865802BR5913Fulano de Tal6008BRASILIA62070503***63041D3D"
+	got, err := p.BRCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("BRCode =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestBRCodeFields(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Payload
+		want map[string]string
+	}{
+		{
+			name: "estático com valor e txid",
+			p: Payload{
+				Key: "+5511999998888", MerchantName: "Joalheria São João", MerchantCity: "São Paulo",
+				Amount: 129990, TxID: "pedido-123/ABC", Description: "Pedido 123",
+			},
+			want: map[string]string{
+				"00": "01", "26": "0014br.gov.bcb.pix0114+55119999988880210Pedido 123",
+				"52": "0000", "53": "986", "54": "1299.90", "58": "BR",
+				"59": "Joalheria Sao Joao", "60": "Sao Paulo", "62": "0512pedido123ABC",
+			},
+		},
+		{
+			name: "centavos com zero à esquerda",
+			p:    Payload{Key: "chave@loja.com", MerchantName: "Loja", MerchantCity: "Recife", Amount: 5},
+			want: map[string]string{"54": "0.05", "62": "0503***"},
+		},
+		{
+			name: "dinâmico",
+			p: Payload{
+				URL: "https://pix.example.com/qr/v2/abc123", MerchantName: "Loja", MerchantCity: "Recife",
+				Amount: 1000, TxID: "ignorado",
+			},
+			want: map[string]string{
+				"01": "12", "26": "0014br.gov.bcb.pix2528pix.example.com/qr/v2/abc123",
+				"54": "10.00", "62": "0503***",
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, err := c.p.BRCode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			fields := parseTLV(t, code)
+			for id, w := range c.want {
+				if fields[id] != w {
+					t.Errorf("campo %s = %q, want %q", id, fields[id], w)
+				}
+			}
+			body := code[:len(code)-4]
+			if crc := fmt.Sprintf("%04X", CRC16(body)); fields["63"] != crc {
+				t.Errorf("CRC = %s, want %s", fields["63"], crc)
+			}
+		})
+	}
+}
+
+// parseTLV separa os campos de primeiro nível (ID + tamanho + valor).
+func parseTLV(t *testing.T, s string) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	for len(s) > 0 {
+		if len(s) < 4 {
+			t.Fatalf("campo truncado: %q", s)
+		}
+		var n int
+		if _, err := fmt.Sscanf(s[2:4], "%d", &n); err != nil || len(s) < 4+n {
+			t.Fatalf("tamanho inválido em %q", s)
+		}
+		out[s[:2]] = s[4 : 4+n]
+		s = s[4+n:]
+	}
+	return out
+}
+
+func TestBRCodeErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		p    Payload
+	}{
+		{"sem nome", Payload{Key: "k", MerchantCity: "Recife"}},
+		{"sem cidade", Payload{Key: "k", MerchantName: "Loja"}},
+		{"estático sem chave", Payload{MerchantName: "Loja", MerchantCity: "Recife"}},
+		{"chave longa demais", Payload{Key: strings.Repeat("k", 90), MerchantName: "Loja", MerchantCity: "Recife"}},
+	}
+	for _, c := range cases {
+		if _, err := c.p.BRCode(); err == nil {
+			t.Errorf("%s: esperava erro", c.name)
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	cases := []struct {
+		in   string
+		max  int
+		want string
+	}{
+		{"  São Paulo ", 15, "Sao Paulo"},
+		{"Açaí & Cia", 25, "Acai & Cia"},
+		{"Loja 🛍️ Bela", 25, "Loja  Bela"},
+		{"Nome de Loja Bem Comprido Demais", 25, "Nome de Loja Bem Comprido"},
+		{"Joalheria Ltda ", 10, "Joalheria"},
+	}
+	for _, c := range cases {
+		if got := sanitize(c.in, c.max); got != c.want {
+			t.Errorf("sanitize(%q, %d) = %q, want %q", c.in, c.max, got, c.want)
+		}
+	}
+}
+
+func TestTxID(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", "***"},
+		{"---", "***"},
+		{"pedido-123/ABC", "pedido123ABC"},
+		{strings.Repeat("a1", 20), strings.Repeat("a1", 12) + "a"},
+	}
+	for _, c := range cases {
+		if got := txID(c.in); got != c.want {
+			t.Errorf("txID(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,408 @@
+// Package qrcode gera QR Codes (modo byte, correção de erro nível M) em PNG.
+// Cobre as versões 1 a 20 (até 666 bytes), suficiente para BR Codes Pix.
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+)
+
+// ErrTooLong indica que o conteúdo não cabe na maior versão suportada.
+var ErrTooLong = errors.New("qrcode: content too long")
+
+// Code é a matriz de módulos de um QR Code.
+type Code struct {
+	Size    int
+	modules [][]bool
+	isFunc  [][]bool
+}
+
+// Dark informa se o módulo (x, y) é escuro.
+func (c *Code) Dark(x, y int) bool { return c.modules[y][x] }
+
+// blockSpec descreve os blocos de correção de erro de uma versão (nível M).
+type blockSpec struct {
+	ecLen           int
+	g1Blocks, g1Len int
+	g2Blocks, g2Len int
+}
+
+// Tabela ISO/IEC 18004 para o nível M, versões 1..20.
+var specsM = []blockSpec{
+	{10, 1, 16, 0, 0}, {16, 1, 28, 0, 0}, {26, 1, 44, 0, 0}, {18, 2, 32, 0, 0},
+	{24, 2, 43, 0, 0}, {16, 4, 27, 0, 0}, {18, 4, 31, 0, 0}, {22, 2, 38, 2, 39},
+	{22, 3, 36, 2, 37}, {26, 4, 43, 1, 44}, {30, 1, 50, 4, 51}, {22, 6, 36, 2, 37},
+	{22, 8, 37, 1, 38}, {24, 4, 40, 5, 41}, {24, 5, 41, 5, 42}, {28, 7, 45, 3, 46},
+	{28, 10, 46, 1, 47}, {26, 9, 43, 4, 44}, {26, 3, 44, 11, 45}, {26, 3, 41, 13, 42},
+}
+
+var alignPos = [][]int{
+	nil, {6, 18}, {6, 22}, {6, 26}, {6, 30}, {6, 34}, {6, 22, 38}, {6, 24, 42},
+	{6, 26, 46}, {6, 28, 50}, {6, 30, 54}, {6, 32, 58}, {6, 34, 62}, {6, 26, 46, 66},
+	{6, 26, 48, 70}, {6, 26, 50, 74}, {6, 30, 54, 78}, {6, 30, 56, 82}, {6, 30, 58, 86},
+	{6, 34, 62, 90},
+}
+
+func (s blockSpec) dataLen() int { return s.g1Blocks*s.g1Len + s.g2Blocks*s.g2Len }
+
+// Encode gera o QR Code de menor versão que comporta o conteúdo.
+func Encode(content string) (*Code, error) {
+	data := []byte(content)
+	for v := 1; v <= len(specsM); v++ {
+		spec := specsM[v-1]
+		countBits := 8
+		if v >= 10 {
+			countBits = 16
+		}
+		if 4+countBits+8*len(data) > spec.dataLen()*8 {
+			continue
+		}
+		cw := buildCodewords(data, countBits, spec)
+		return build(v, cw), nil
+	}
+	return nil, ErrTooLong
+}
+
+// bitBuffer acumula bits em ordem MSB-first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(val, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (val>>uint(i))&1 == 1)
+	}
+}
+
+func buildCodewords(data []byte, countBits int, spec blockSpec) []byte {
+	capBits := spec.dataLen() * 8
+	var bb bitBuffer
+	bb.append(0x4, 4) // modo byte
+	bb.append(len(data), countBits)
+	for _, c := range data {
+		bb.append(int(c), 8)
+	}
+	// terminador e alinhamento em byte
+	term := capBits - len(bb)
+	if term > 4 {
+		term = 4
+	}
+	bb.append(0, term)
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capBits; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+	raw := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			raw[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+
+	// divide em blocos, calcula Reed-Solomon e intercala
+	div := rsDivisor(spec.ecLen)
+	var blocks, ecs [][]byte
+	off := 0
+	for g, n := range []int{spec.g1Blocks, spec.g2Blocks} {
+		l := spec.g1Len
+		if g == 1 {
+			l = spec.g2Len
+		}
+		for i := 0; i < n; i++ {
+			blk := raw[off : off+l]
+			off += l
+			blocks = append(blocks, blk)
+			ecs = append(ecs, rsRemainder(blk, div))
+		}
+	}
+	var out []byte
+	for i := 0; i < spec.g2Len || i < spec.g1Len; i++ {
+		for _, blk := range blocks {
+			if i < len(blk) {
+				out = append(out, blk[i])
+			}
+		}
+	}
+	for i := 0; i < spec.ecLen; i++ {
+		for _, ec := range ecs {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+func build(version int, codewords []byte) *Code {
+	size := version*4 + 17
+	c := &Code{Size: size, modules: grid(size), isFunc: grid(size)}
+	c.drawFunctionPatterns(version)
+	c.drawCodewords(codewords)
+
+	best, bestPenalty := 0, -1
+	for m := 0; m < 8; m++ {
+		c.applyMask(m)
+		c.drawFormatBits(m)
+		if p := c.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = m, p
+		}
+		c.applyMask(m) // desfaz (XOR)
+	}
+	c.applyMask(best)
+	c.drawFormatBits(best)
+	return c
+}
+
+func grid(n int) [][]bool {
+	g := make([][]bool, n)
+	for i := range g {
+		g[i] = make([]bool, n)
+	}
+	return g
+}
+
+func (c *Code) setFunc(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunc[y][x] = true
+}
+
+func (c *Code) drawFunctionPatterns(version int) {
+	for i := 0; i < c.Size; i++ {
+		c.setFunc(6, i, i%2 == 0)
+		c.setFunc(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.Size-4, 3)
+	c.drawFinder(3, c.Size-4)
+	pos := alignPos[version-1]
+	n := len(pos)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if (i == 0 && j == 0) || (i == 0 && j == n-1) || (i == n-1 && j == 0) {
+				continue
+			}
+			c.drawAlignment(pos[i], pos[j])
+		}
+	}
+	c.drawFormatBits(0) // reserva as áreas de formato
+	if version >= 7 {
+		rem := version
+		for i := 0; i < 12; i++ {
+			rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+		}
+		bits := version<<12 | rem
+		for i := 0; i < 18; i++ {
+			dark := (bits>>uint(i))&1 == 1
+			a, b := c.Size-11+i%3, i/3
+			c.setFunc(a, b, dark)
+			c.setFunc(b, a, dark)
+		}
+	}
+}
+
+func (c *Code) drawFinder(cx, cy int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			x, y := cx+dx, cy+dy
+			if x < 0 || y < 0 || x >= c.Size || y >= c.Size {
+				continue
+			}
+			d := maxInt(abs(dx), abs(dy))
+			c.setFunc(x, y, d != 2 && d != 4)
+		}
+	}
+}
+
+func (c *Code) drawAlignment(cx, cy int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunc(cx+dx, cy+dy, maxInt(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// drawFormatBits grava nível M (00) + máscara com BCH(15,5).
+func (c *Code) drawFormatBits(mask int) {
+	data := 0<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>uint(i))&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		c.setFunc(8, i, bit(i))
+	}
+	c.setFunc(8, 7, bit(6))
+	c.setFunc(8, 8, bit(7))
+	c.setFunc(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		c.setFunc(14-i, 8, bit(i))
+	}
+	for i := 0; i < 8; i++ {
+		c.setFunc(c.Size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunc(8, c.Size-15+i, bit(i))
+	}
+	c.setFunc(8, c.Size-8, true) // módulo escuro fixo
+}
+
+func (c *Code) drawCodewords(data []byte) {
+	i := 0
+	for right := c.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.Size - 1 - vert
+				}
+				if c.isFunc[y][x] || i >= len(data)*8 {
+					continue
+				}
+				c.modules[y][x] = (data[i>>3]>>uint(7-i&7))&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (c *Code) applyMask(mask int) {
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.isFunc[y][x] {
+				continue
+			}
+			var inv bool
+			switch mask {
+			case 0:
+				inv = (x+y)%2 == 0
+			case 1:
+				inv = y%2 == 0
+			case 2:
+				inv = x%3 == 0
+			case 3:
+				inv = (x+y)%3 == 0
+			case 4:
+				inv = (x/3+y/2)%2 == 0
+			case 5:
+				inv = x*y%2+x*y%3 == 0
+			case 6:
+				inv = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				inv = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if inv {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty aplica as regras N1..N4 da norma para escolher a máscara.
+func (c *Code) penalty() int {
+	n := c.Size
+	p := 0
+	line := func(get func(i int) bool) {
+		run := 1
+		for i := 1; i <= n; i++ {
+			if i < n && get(i) == get(i-1) {
+				run++
+				continue
+			}
+			if run >= 5 {
+				p += 3 + run - 5
+			}
+			run = 1
+		}
+		// padrão tipo finder: 1011101 com 4 claros de um dos lados
+		for i := 0; i+10 < n; i++ {
+			a := []bool{get(i), get(i + 1), get(i + 2), get(i + 3), get(i + 4), get(i + 5), get(i + 6), get(i + 7), get(i + 8), get(i + 9), get(i + 10)}
+			if matches(a, finderA) || matches(a, finderB) {
+				p += 40
+			}
+		}
+	}
+	for y := 0; y < n; y++ {
+		yy := y
+		line(func(i int) bool { return c.modules[yy][i] })
+	}
+	for x := 0; x < n; x++ {
+		xx := x
+		line(func(i int) bool { return c.modules[i][xx] })
+	}
+	dark := 0
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			m := c.modules[y][x]
+			if m {
+				dark++
+			}
+			if x+1 < n && y+1 < n && m == c.modules[y][x+1] && m == c.modules[y+1][x] && m == c.modules[y+1][x+1] {
+				p += 3
+			}
+		}
+	}
+	k := abs(dark*20-n*n*10) / (n * n)
+	p += k * 10
+	return p
+}
+
+var (
+	finderA = []bool{true, false, true, true, true, false, true, false, false, false, false}
+	finderB = []bool{false, false, false, false, true, false, true, true, true, false, true}
+)
+
+func matches(a, b []bool) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// PNG renderiza o código com scale pixels por módulo e zona de silêncio de 4 módulos.
+func (c *Code) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		scale = 8
+	}
+	const quiet = 4
+	dim := (c.Size + 2*quiet) * scale
+	img := image.NewPaletted(image.Rect(0, 0, dim, dim), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetColorIndex((x+quiet)*scale+dx, (y+quiet)*scale+dy, 1)
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
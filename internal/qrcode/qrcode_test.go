@@ -0,0 +1,164 @@
+package qrcode
+
+import (
+	"bytes"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Vetor do tutorial da Thonky (HELLO WORLD, versão 1-M): 16 codewords de
+// dados e os 10 de correção esperados.
+func TestRSRemainder(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(10)); !reflect.DeepEqual(got, want) {
+		t.Errorf("rsRemainder = %v, want %v", got, want)
+	}
+}
+
+func TestGFMul(t *testing.T) {
+	cases := []struct{ x, y, want byte }{
+		{0, 7, 0},
+		{1, 0x53, 0x53},
+		{2, 0x80, 0x1D}, // estoura e reduz por 0x11D
+		{0x02, 0x02, 0x04},
+		{0x8E, 0x02, 0x01}, // α^254 · α = 1
+	}
+	for _, c := range cases {
+		if got := gfMul(c.x, c.y); got != c.want {
+			t.Errorf("gfMul(%#x, %#x) = %#x, want %#x", c.x, c.y, got, c.want)
+		}
+	}
+}
+
+// Sequências de formato da ISO/IEC 18004 (tabela C.1) para o nível M.
+func TestFormatBits(t *testing.T) {
+	want := []string{
+		"101010000010010", "101000100100101", "101111001111100", "101101101001011",
+		"100010111111001", "100000011001110", "100111110010111", "100101010100000",
+	}
+	for mask, w := range want {
+		c := &Code{Size: 21, modules: grid(21), isFunc: grid(21)}
+		c.drawFormatBits(mask)
+		// cópia ao redor do finder superior esquerdo, do bit 14 ao 0
+		var b strings.Builder
+		for x := 0; x <= 5; x++ {
+			b.WriteString(bit(c.Dark(x, 8)))
+		}
+		b.WriteString(bit(c.Dark(7, 8)) + bit(c.Dark(8, 8)) + bit(c.Dark(8, 7)))
+		for y := 5; y >= 0; y-- {
+			b.WriteString(bit(c.Dark(8, y)))
+		}
+		if got := b.String(); got != w {
+			t.Errorf("mask %d: formato %s, want %s", mask, got, w)
+		}
+	}
+}
+
+// Versão 7: 000111110010010100 (ISO/IEC 18004, tabela D.1).
+func TestVersionBits(t *testing.T) {
+	c := &Code{Size: 45, modules: grid(45), isFunc: grid(45)}
+	c.drawFunctionPatterns(7)
+	var b strings.Builder
+	for i := 17; i >= 0; i-- {
+		b.WriteString(bit(c.Dark(c.Size-11+i%3, i/3)))
+	}
+	if got := b.String(); got != "000111110010010100" {
+		t.Errorf("versão 7 = %s", got)
+	}
+}
+
+func bit(dark bool) string {
+	if dark {
+		return "1"
+	}
+	return "0"
+}
+
+// Capacidade em bytes no nível M: a menor versão que comporta o conteúdo.
+func TestEncodeVersion(t *testing.T) {
+	cases := []struct {
+		n       int
+		version int
+	}{
+		{1, 1}, {14, 1}, {15, 2}, {26, 2}, {27, 3},
+		{152, 8}, {153, 9}, {213, 10}, {214, 11}, {666, 20},
+	}
+	for _, c := range cases {
+		code, err := Encode(strings.Repeat("a", c.n))
+		if err != nil {
+			t.Fatalf("Encode(%d bytes): %v", c.n, err)
+		}
+		if v := (code.Size - 17) / 4; v != c.version {
+			t.Errorf("Encode(%d bytes): versão %d, want %d", c.n, v, c.version)
+		}
+	}
+	if _, err := Encode(strings.Repeat("a", 667)); err != ErrTooLong {
+		t.Errorf("Encode(667 bytes) err = %v, want ErrTooLong", err)
+	}
+}
+
+// Matriz de "PIX" conferida com um decodificador independente (zxing).
+func TestEncodeGolden(t *testing.T) {
+	want := []string{
+		"#######.......#######",
+		"#.....#..##...#.....#",
+		"#.###.#.#..##.#.###.#",
+		"#.###.#.#..##.#.###.#",
+		"#.###.#.#.#.#.#.###.#",
+		"#.....#.#.##..#.....#",
+		"#######.#.#.#.#######",
+		"........###..........",
+		"#.#####...##..#####..",
+		"...##..########......",
+		"...##.###...#.##.###.",
+		"####.#..#..####..##..",
+		"#######..#..#..#.##..",
+		"........#...#..#...#.",
+		"#######..#.#.#..#.##.",
+		"#.....#.#......##.###",
+		"#.###.#.####.#..#.#..",
+		"#.###.#.##.####..#...",
+		"#.###.#.#...#.##.....",
+		"#.....#...#####..#...",
+		"#######.#...#..#..##.",
+	}
+	c, err := Encode("PIX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for y := 0; y < c.Size; y++ {
+		var b strings.Builder
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		if got := b.String(); got != want[y] {
+			t.Errorf("linha %2d = %s, want %s", y, got, want[y])
+		}
+	}
+}
+
+func TestPNG(t *testing.T) {
+	c, err := Encode("PIX")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := c.PNG(4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 21 módulos + 4 de zona de silêncio de cada lado, 4 px por módulo
+	if s := img.Bounds().Dx(); s != (21+8)*4 {
+		t.Errorf("largura = %d", s)
+	}
+}
@@ -0,0 +1,87 @@
+package qualify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"pac-lead-agent/internal/clients"
+)
+
+// Extractor roda a extração estruturada sobre as mensagens recentes.
+type Extractor struct {
+	AI    *clients.OpenAI
+	Model string
+}
+
+const systemPrompt = `Você extrai dados de qualificação de leads de conversas de vendas no WhatsApp (pt-BR).
+Considere apenas o que o CLIENTE disse ou confirmou; nunca invente.
+Para cada campo devolva "value" (string vazia se não houver informação) e "confidence" entre 0 e 1.
+- need: o que o cliente precisa/procura, em poucas palavras
+- budget: orçamento mencionado (ex.: "até R$ 500")
+- urgency: "alta", "media" ou "baixa"
+- decision_maker: "sim" se o cliente decide a compra, "nao" se depende de outra pessoa
+- name, email, city: dados informados pelo cliente
+- document: CPF ou CNPJ informado pelo cliente, somente dígitos`
+
+func fieldSchema() map[string]any {
+	return map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"value":      map[string]any{"type": "string"},
+			"confidence": map[string]any{"type": "number"},
+		},
+		"required":             []string{"value", "confidence"},
+		"additionalProperties": false,
+	}
+}
+
+var fieldNames = []string{"need", "budget", "urgency", "decision_maker", "name", "email", "city", "document"}
+
+func schema() map[string]any {
+	props := map[string]any{}
+	for _, n := range fieldNames {
+		props[n] = fieldSchema()
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           props,
+		"required":             fieldNames,
+		"additionalProperties": false,
+	}
+}
+
+// Extract lê as últimas mensagens da thread e devolve o perfil extraído
+// (sem score; use Profile.Merge para consolidar).
+func (e Extractor) Extract(ctx context.Context, threadID string, window int) (Profile, error) {
+	msgs, err := e.AI.RecentMessages(ctx, threadID, window)
+	if err != nil {
+		return Profile{}, err
+	}
+	if len(msgs) == 0 {
+		return Profile{}, nil
+	}
+	var b strings.Builder
+	for _, m := range msgs {
+		who := "Cliente"
+		if m.Role == "assistant" {
+			who = "Atendente"
+		}
+		fmt.Fprintf(&b, "%s: %s\n", who, m.Text)
+	}
+	raw, err := e.AI.ChatJSON(ctx, e.Model, systemPrompt, b.String(), "lead_profile", schema())
+	if err != nil {
+		return Profile{}, err
+	}
+	var p Profile
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return Profile{}, err
+	}
+	now := time.Now()
+	for _, f := range p.fields() {
+		f.UpdatedAt = now
+	}
+	return p, nil
+}
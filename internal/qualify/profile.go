@@ -0,0 +1,119 @@
+// Package qualify extrai dados de qualificação (BANT) e cadastro do lead a
+// partir da conversa e calcula uma pontuação que gera as tags hot/warm/cold.
+package qualify
+
+import (
+	"strings"
+	"time"
+)
+
+// Field é um dado extraído com a confiança (0..1) do extrator.
+type Field struct {
+	Value      string    `json:"value"`
+	Confidence float64   `json:"confidence"`
+	UpdatedAt  time.Time `json:"updated_at,omitempty"`
+}
+
+func (f Field) Known() bool { return strings.TrimSpace(f.Value) != "" }
+
+// Profile é o perfil consolidado do lead.
+type Profile struct {
+	Need          Field `json:"need"`
+	Budget        Field `json:"budget"`
+	Urgency       Field `json:"urgency"`        // alta | media | baixa
+	DecisionMaker Field `json:"decision_maker"` // sim | nao
+	Name          Field `json:"name"`
+	Email         Field `json:"email"`
+	City          Field `json:"city"`
+	Document      Field `json:"document"` // CPF ou CNPJ (somente dígitos)
+
+	Score int       `json:"score"`
+	Tag   string    `json:"tag"`
+	At    time.Time `json:"updated_at"`
+}
+
+// fields expõe os campos por nome (para merge e CRM).
+func (p *Profile) fields() map[string]*Field {
+	return map[string]*Field{
+		"need": &p.Need, "budget": &p.Budget, "urgency": &p.Urgency, "decision_maker": &p.DecisionMaker,
+		"name": &p.Name, "email": &p.Email, "city": &p.City, "document": &p.Document,
+	}
+}
+
+// MinConfidence descarta extrações muito incertas.
+const MinConfidence = 0.5
+
+// Merge incorpora uma nova extração: um valor novo substitui o atual quando tem
+// confiança suficiente e não menor que a do valor atual (ou quando o atual é
+// antigo, pois o lead pode mudar de ideia). Retorna true se algo mudou.
+func (p *Profile) Merge(next Profile, now time.Time) bool {
+	changed := false
+	cur := p.fields()
+	for name, nf := range next.fields() {
+		if !nf.Known() || nf.Confidence < MinConfidence {
+			continue
+		}
+		of := cur[name]
+		if of.Known() && strings.EqualFold(of.Value, nf.Value) {
+			if nf.Confidence > of.Confidence {
+				of.Confidence = nf.Confidence
+			}
+			continue
+		}
+		stale := now.Sub(of.UpdatedAt) > 24*time.Hour
+		if !of.Known() || nf.Confidence >= of.Confidence || stale {
+			*of = Field{Value: strings.TrimSpace(nf.Value), Confidence: nf.Confidence, UpdatedAt: now}
+			changed = true
+		}
+	}
+	if changed {
+		p.Score, p.Tag = Score(*p)
+		p.At = now
+	}
+	return changed
+}
+
+// Score pontua o BANT (0..100), ponderando pela confiança, e define a tag.
+func Score(p Profile) (int, string) {
+	var s float64
+	if p.Need.Known() {
+		s += 30 * p.Need.Confidence
+	}
+	if p.Budget.Known() {
+		s += 25 * p.Budget.Confidence
+	}
+	switch strings.ToLower(p.Urgency.Value) {
+	case "alta":
+		s += 25 * p.Urgency.Confidence
+	case "media", "média":
+		s += 15 * p.Urgency.Confidence
+	case "baixa":
+		s += 5 * p.Urgency.Confidence
+	}
+	switch strings.ToLower(p.DecisionMaker.Value) {
+	case "sim":
+		s += 20 * p.DecisionMaker.Confidence
+	case "nao", "não":
+		s += 5 * p.DecisionMaker.Confidence
+	}
+	score := int(s + 0.5)
+	switch {
+	case score >= 70:
+		return score, "hot"
+	case score >= 40:
+		return score, "warm"
+	}
+	return score, "cold"
+}
+
+// Values achata o perfil em mapa simples (valores e confiança) para o CRM.
+func (p Profile) Values() map[string]any {
+	out := map[string]any{"score": p.Score, "tag": p.Tag}
+	for name, f := range (&p).fields() {
+		if f.Known() {
+			out[name] = f.Value
+			out[name+"_confidence"] = f.Confidence
+		}
+	}
+	return out
+}
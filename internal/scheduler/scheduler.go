@@ -0,0 +1,218 @@
+// Package scheduler agenda tarefas futuras por conversa (ex.: follow-ups) no
+// state.Store. Cada réplica varre os jobs vencidos periodicamente; um lease por
+// job garante que só uma delas execute cada um.
+package scheduler
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+const (
+	keyPrefix   = "sched:"
+	leasePrefix = "lease:sched:"
+
+	defaultInterval = time.Minute
+	defaultLease    = 5 * time.Minute
+	// defaultWorkers limita os jobs simultâneos por réplica.
+	defaultWorkers = 8
+	// retryDelay adia um job cujo handler falhou.
+	retryDelay = 10 * time.Minute
+	// jobRetention mantém o job no store além do vencimento (réplicas fora do ar).
+	jobRetention = 7 * 24 * time.Hour
+)
+
+// Job é uma tarefa agendada. ID identifica a conversa dentro do Kind (um job
+// por conversa e tipo: agendar de novo substitui o anterior).
+type Job struct {
+	Kind    string    `json:"kind"`
+	ID      string    `json:"id"`
+	DueAt   time.Time `json:"due_at"`
+	Attempt int       `json:"attempt"`
+	// Since marca o início da contagem (ex.: última resposta do bot).
+	Since time.Time         `json:"since"`
+	Data  map[string]string `json:"data,omitempty"`
+}
+
+func (j Job) key() string {
+	return keyPrefix + j.Kind + ":" + j.ID
+}
+
+// Handler executa um job vencido. É responsável por reagendar (Schedule) ou
+// encerrar (Cancel); se retornar erro, o job é adiado e tentado de novo.
+type Handler func(ctx context.Context, s *Scheduler, job Job) error
+
+type Scheduler struct {
+	Store    state.Store
+	Interval time.Duration
+	Lease    time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+	// slots limita a concorrência: um job longo (campanha) não segura os demais
+	slots chan struct{}
+	wg    sync.WaitGroup
+}
+
+func New(st state.Store) *Scheduler {
+	return &Scheduler{
+		Store: st, Interval: defaultInterval, Lease: defaultLease,
+		handlers: map[string]Handler{}, slots: make(chan struct{}, defaultWorkers),
+	}
+}
+
+var (
+	defaultOnce sync.Once
+	defaultSch  *Scheduler
+)
+
+// Default devolve o scheduler do processo, sobre state.Default().
+func Default() *Scheduler {
+	defaultOnce.Do(func() { defaultSch = New(state.Default()) })
+	return defaultSch
+}
+
+// Handle registra o handler de um tipo de job.
+func (s *Scheduler) Handle(kind string, h Handler) {
+	s.mu.Lock()
+	s.handlers[kind] = h
+	s.mu.Unlock()
+}
+
+// Schedule grava (ou substitui) o job.
+func (s *Scheduler) Schedule(ctx context.Context, j Job) error {
+	if j.Kind == "" || j.ID == "" {
+		return errors.New("scheduler: job sem kind/id")
+	}
+	ttl := time.Until(j.DueAt) + jobRetention
+	if ttl < jobRetention {
+		ttl = jobRetention
+	}
+	return s.Store.Set(ctx, j.key(), j, ttl)
+}
+
+// Get devolve o job agendado para a conversa, se houver.
+func (s *Scheduler) Get(ctx context.Context, kind, id string) (Job, bool) {
+	var j Job
+	ok, err := s.Store.Get(ctx, Job{Kind: kind, ID: id}.key(), &j)
+	return j, ok && err == nil
+}
+
+// Cancel remove o job (ex.: o lead respondeu).
+func (s *Scheduler) Cancel(ctx context.Context, kind, id string) error {
+	return s.Store.Delete(ctx, Job{Kind: kind, ID: id}.key())
+}
+
+// Run varre os jobs vencidos a cada Interval até o contexto terminar e então
+// espera os jobs em andamento.
+func (s *Scheduler) Run(ctx context.Context) {
+	t := time.NewTicker(s.Interval)
+	defer t.Stop()
+	for {
+		s.Tick(ctx)
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// Tick executa uma varredura: cada job vencido roda sob lease (réplicas
+// concorrentes não o executam em dobro), em goroutine, até defaultWorkers ao
+// mesmo tempo. Sem vaga, os jobs restantes ficam para a próxima varredura.
+func (s *Scheduler) Tick(ctx context.Context) {
+	keys, err := s.Store.Keys(ctx, keyPrefix)
+	if err != nil {
+		log.Println("scheduler: keys:", err)
+		return
+	}
+	now := time.Now()
+	for _, k := range keys {
+		if ctx.Err() != nil {
+			return
+		}
+		var j Job
+		if ok, err := s.Store.Get(ctx, k, &j); !ok || err != nil || j.DueAt.After(now) {
+			continue
+		}
+		s.mu.RLock()
+		h := s.handlers[j.Kind]
+		s.mu.RUnlock()
+		if h == nil {
+			continue
+		}
+		select {
+		case s.slots <- struct{}{}:
+		default:
+			return
+		}
+		release, ok := s.acquire(ctx, leasePrefix+strings.TrimPrefix(k, keyPrefix))
+		if !ok {
+			<-s.slots
+			continue
+		}
+		s.wg.Add(1)
+		go func(h Handler, j Job) {
+			defer s.wg.Done()
+			defer func() { <-s.slots }()
+			s.run(ctx, h, j)
+			release()
+		}(h, j)
+	}
+}
+
+// RunNow executa o job imediatamente (sob o mesmo lease da varredura), sem
+// esperar o próximo Tick. Não faz nada se outra réplica já o estiver executando.
+func (s *Scheduler) RunNow(ctx context.Context, kind, id string) {
+	j, ok := s.Get(ctx, kind, id)
+	if !ok {
+		return
+	}
+	s.mu.RLock()
+	h := s.handlers[kind]
+	s.mu.RUnlock()
+	if h == nil {
+		return
+	}
+	release, ok := s.acquire(ctx, leasePrefix+kind+":"+id)
+	if !ok {
+		return
+	}
+	s.run(ctx, h, j)
+	release()
+}
+
+// acquire pega o lease com um token próprio; release só o apaga se o token
+// ainda for o mesmo (um lease vencido e tomado por outra réplica fica intacto).
+func (s *Scheduler) acquire(ctx context.Context, lease string) (release func(), ok bool) {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	token := hex.EncodeToString(b)
+	if ok, err := s.Store.SetNX(ctx, lease, token, s.Lease); !ok || err != nil {
+		return nil, false
+	}
+	return func() { _, _ = s.Store.DeleteIf(context.Background(), lease, token) }, true
+}
+
+func (s *Scheduler) run(ctx context.Context, h Handler, j Job) {
+	jctx, cancel := context.WithTimeout(ctx, s.Lease)
+	defer cancel()
+	if err := h(jctx, s, j); err != nil {
+		log.Println("scheduler:", j.Kind, j.ID, err)
+		// só adia se o handler não mexeu no job
+		if cur, ok := s.Get(ctx, j.Kind, j.ID); ok && cur.DueAt.Equal(j.DueAt) && cur.Attempt == j.Attempt {
+			cur.DueAt = time.Now().Add(retryDelay)
+			_ = s.Schedule(ctx, cur)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+// Package shipping calcula opções de frete por CEP a partir das tabelas de
+// cada tenant (faixas de CEP, faixas de peso e frete grátis).
+package shipping
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCEP indica CEP com tamanho errado ou claramente inválido.
+var ErrInvalidCEP = errors.New("CEP inválido")
+
+// NormalizeCEP devolve os 8 dígitos do CEP ("01310-100" → "01310100").
+func NormalizeCEP(s string) (string, error) {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case r == '-' || r == '.' || r == ' ':
+		default:
+			return "", ErrInvalidCEP
+		}
+	}
+	cep := b.String()
+	if len(cep) != 8 || cep == "00000000" || strings.Count(cep, cep[:1]) == 8 {
+		return "", ErrInvalidCEP
+	}
+	return cep, nil
+}
+
+// FormatCEP formata 8 dígitos como "01310-100".
+func FormatCEP(cep string) string {
+	if len(cep) != 8 {
+		return cep
+	}
+	return cep[:5] + "-" + cep[5:]
+}
@@ -0,0 +1,141 @@
+package shipping
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"pac-lead-agent/internal/money"
+)
+
+// Price é um valor em reais aceito como número ou string nas settings.
+type Price money.Amount
+
+func (p *Price) UnmarshalJSON(b []byte) error {
+	var v any
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	a, ok := money.Parse(v)
+	if !ok {
+		return fmt.Errorf("shipping: invalid price %s", string(b))
+	}
+	*p = Price(a)
+	return nil
+}
+
+// Table é a tabela de frete do tenant (settings "shipping").
+type Table struct {
+	// FreeAbove concede frete grátis em todas as regras quando o subtotal atinge o valor.
+	FreeAbove Price  `json:"free_above"`
+	Rules     []Rule `json:"rules"`
+}
+
+// Rule é uma modalidade de entrega válida para uma faixa de CEP.
+type Rule struct {
+	Name    string `json:"name"`
+	CEPFrom string `json:"cep_from"`
+	CEPTo   string `json:"cep_to"`
+	// Bands são faixas de peso em ordem crescente; a primeira com MaxKg ≥ peso vale.
+	Bands   []Band `json:"weight_bands"`
+	DaysMin int    `json:"days_min"`
+	DaysMax int    `json:"days_max"`
+	// FreeAbove sobrepõe o limite geral para esta modalidade.
+	FreeAbove Price `json:"free_above"`
+}
+
+// Band é uma faixa de peso; MaxKg 0 significa "sem limite".
+type Band struct {
+	MaxKg float64 `json:"max_kg"`
+	Price Price   `json:"price"`
+}
+
+// Option é uma cotação de frete.
+type Option struct {
+	Name    string       `json:"name"`
+	CEP     string       `json:"cep"`
+	Price   money.Amount `json:"price"`
+	DaysMin int          `json:"days_min"`
+	DaysMax int          `json:"days_max"`
+	Free    bool         `json:"free"`
+}
+
+// Delivery descreve o prazo ("3 a 7 dias úteis").
+func (o Option) Delivery() string {
+	switch {
+	case o.DaysMin > 0 && o.DaysMax > o.DaysMin:
+		return fmt.Sprintf("%d a %d dias úteis", o.DaysMin, o.DaysMax)
+	case o.DaysMax > 0:
+		return fmt.Sprintf("até %d dias úteis", o.DaysMax)
+	case o.DaysMin > 0:
+		return fmt.Sprintf("%d dias úteis", o.DaysMin)
+	}
+	return "prazo a combinar"
+}
+
+// Quote devolve as opções disponíveis para o CEP, peso (kg) e subtotal do
+// pedido, ordenadas do menor preço para o maior.
+func (t Table) Quote(cep string, weightKg float64, subtotal money.Amount) ([]Option, error) {
+	cep, err := NormalizeCEP(cep)
+	if err != nil {
+		return nil, err
+	}
+	var out []Option
+	for _, r := range t.Rules {
+		if !r.covers(cep) {
+			continue
+		}
+		band, ok := r.band(weightKg)
+		if !ok {
+			continue
+		}
+		opt := Option{
+			Name:    r.Name,
+			CEP:     cep,
+			Price:   money.Amount(band.Price),
+			DaysMin: r.DaysMin,
+			DaysMax: r.DaysMax,
+		}
+		free := r.FreeAbove
+		if free <= 0 {
+			free = t.FreeAbove
+		}
+		if free > 0 && subtotal >= money.Amount(free) {
+			opt.Price, opt.Free = 0, true
+		}
+		out = append(out, opt)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Price < out[j].Price })
+	return out, nil
+}
+
+func (r Rule) covers(cep string) bool {
+	if r.CEPFrom == "" && r.CEPTo == "" {
+		return true // regra nacional
+	}
+	from, to := digits(r.CEPFrom), digits(r.CEPTo)
+	if len(from) != 8 || len(to) != 8 {
+		return false
+	}
+	// CEPs de 8 dígitos comparam corretamente como string
+	return cep >= from && cep <= to
+}
+
+func (r Rule) band(weightKg float64) (Band, bool) {
+	for _, b := range r.Bands {
+		if b.MaxKg <= 0 || weightKg <= b.MaxKg {
+			return b, true
+		}
+	}
+	return Band{}, false
+}
+
+func digits(s string) string {
+	b := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] >= '0' && s[i] <= '9' {
+			b = append(b, s[i])
+		}
+	}
+	return string(b)
+}
@@ -0,0 +1,121 @@
+package shipping
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"pac-lead-agent/internal/money"
+)
+
+func TestNormalizeCEP(t *testing.T) {
+	cases := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"01310-100", "01310100", true},
+		{"01310100", "01310100", true},
+		{" 01.310-100 ", "01310100", true},
+		{"30140-071", "30140071", true},
+		{"0131010", "", false},
+		{"013101000", "", false},
+		{"01310-10a", "", false},
+		{"00000-000", "", false},
+		{"11111-111", "", false},
+		{"", "", false},
+	}
+	for _, c := range cases {
+		got, err := NormalizeCEP(c.in)
+		if got != c.want || (err == nil) != c.ok {
+			t.Errorf("NormalizeCEP(%q) = %q, %v; want %q, ok=%v", c.in, got, err, c.want, c.ok)
+		}
+	}
+}
+
+func TestFormatCEP(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"01310100", "01310-100"},
+		{"0131", "0131"},
+	}
+	for _, c := range cases {
+		if got := FormatCEP(c.in); got != c.want {
+			t.Errorf("FormatCEP(%q) = %q, want %q", c.in, got, c.want)
+		}
+	}
+}
+
+// tabela no formato das settings do tenant (preços como número ou string).
+const tableJSON = `{
+	"free_above": "500,00",
+	"rules": [
+		{"name": "Motoboy", "cep_from": "01000-000", "cep_to": "05999-999", "days_max": 1,
+		 "weight_bands": [{"max_kg": 5, "price": 15}], "free_above": 200},
+		{"name": "PAC", "days_min": 5, "days_max": 10,
+		 "weight_bands": [{"max_kg": 1, "price": "25,90"}, {"max_kg": 10, "price": 49.9}, {"max_kg": 0, "price": 99}]},
+		{"name": "Sedex", "days_min": 1, "days_max": 3,
+		 "weight_bands": [{"max_kg": 1, "price": 45}, {"max_kg": 10, "price": 80}]}
+	]
+}`
+
+func TestQuote(t *testing.T) {
+	var tbl Table
+	if err := json.Unmarshal([]byte(tableJSON), &tbl); err != nil {
+		t.Fatal(err)
+	}
+	type opt struct {
+		Name  string
+		Price money.Amount
+		Free  bool
+	}
+	cases := []struct {
+		name     string
+		cep      string
+		weight   float64
+		subtotal money.Amount
+		want     []opt
+	}{
+		{"capital leve", "01310-100", 0.5, 10000, []opt{{"Motoboy", 1500, false}, {"PAC", 2590, false}, {"Sedex", 4500, false}}},
+		{"interior leve", "30140-071", 0.5, 10000, []opt{{"PAC", 2590, false}, {"Sedex", 4500, false}}},
+		{"faixa intermediária", "30140-071", 3, 10000, []opt{{"PAC", 4990, false}, {"Sedex", 8000, false}}},
+		{"acima das faixas do Sedex", "30140-071", 25, 10000, []opt{{"PAC", 9900, false}}},
+		{"acima do motoboy", "01310-100", 6, 10000, []opt{{"PAC", 4990, false}, {"Sedex", 8000, false}}},
+		{"grátis da regra", "01310-100", 1, 20000, []opt{{"Motoboy", 0, true}, {"PAC", 2590, false}, {"Sedex", 4500, false}}},
+		{"grátis geral", "30140-071", 1, 50000, []opt{{"PAC", 0, true}, {"Sedex", 0, true}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts, err := tbl.Quote(c.cep, c.weight, c.subtotal)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []opt
+			for _, o := range opts {
+				got = append(got, opt{o.Name, o.Price, o.Free})
+			}
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Quote = %+v, want %+v", got, c.want)
+			}
+		})
+	}
+	if _, err := tbl.Quote("123", 1, 0); err != ErrInvalidCEP {
+		t.Errorf("Quote com CEP inválido: err = %v, want ErrInvalidCEP", err)
+	}
+}
+
+func TestDelivery(t *testing.T) {
+	cases := []struct {
+		min, max int
+		want     string
+	}{
+		{3, 7, "3 a 7 dias úteis"},
+		{0, 2, "até 2 dias úteis"},
+		{2, 2, "até 2 dias úteis"},
+		{4, 0, "4 dias úteis"},
+		{0, 0, "prazo a combinar"},
+	}
+	for _, c := range cases {
+		if got := (Option{DaysMin: c.min, DaysMax: c.max}).Delivery(); got != c.want {
+			t.Errorf("Delivery(%d, %d) = %q, want %q", c.min, c.max, got, c.want)
+		}
+	}
+}
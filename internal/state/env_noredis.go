@@ -0,0 +1,6 @@
+//go:build !redis
+
+package state
+
+// NewFromEnv devolve um Store em memória (build sem a tag "redis").
+func NewFromEnv() Store { return NewMemory() }
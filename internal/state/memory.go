@@ -0,0 +1,131 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Memory é um Store em processo. Adequado para uma única réplica.
+type Memory struct {
+	mu   sync.Mutex
+	data map[string]memEntry
+}
+
+type memEntry struct {
+	val     []byte
+	expires time.Time
+}
+
+func NewMemory() *Memory { return &Memory{data: map[string]memEntry{}} }
+
+func (m *Memory) live(key string, now time.Time) (memEntry, bool) {
+	e, ok := m.data[key]
+	if !ok {
+		return e, false
+	}
+	if !e.expires.IsZero() && now.After(e.expires) {
+		delete(m.data, key)
+		return e, false
+	}
+	return e, true
+}
+
+func (m *Memory) Get(ctx context.Context, key string, out any) (bool, error) {
+	m.mu.Lock()
+	e, ok := m.live(key, time.Now())
+	m.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(e.val, out)
+}
+
+func (m *Memory) Set(ctx context.Context, key string, v any, ttl time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = memEntry{val: b, expires: expiry(ttl)}
+	return nil
+}
+
+func (m *Memory) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *Memory) SetNX(ctx context.Context, key string, v any, ttl time.Duration) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.live(key, time.Now()); ok {
+		return false, nil
+	}
+	m.data[key] = memEntry{val: b, expires: expiry(ttl)}
+	return true, nil
+}
+
+func (m *Memory) DeleteIf(ctx context.Context, key string, v any) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.live(key, time.Now())
+	if !ok || !bytes.Equal(e.val, b) {
+		return false, nil
+	}
+	delete(m.data, key)
+	return true, nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	if e, ok := m.live(key, time.Now()); ok {
+		if err := json.Unmarshal(e.val, &n); err != nil {
+			return 0, err
+		}
+	}
+	n += delta
+	m.data[key] = memEntry{val: strconv.AppendInt(nil, n, 10), expires: expiry(ttl)}
+	return n, nil
+}
+
+func (m *Memory) Keys(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	var out []string
+	for k := range m.data {
+		if strings.HasPrefix(k, prefix) {
+			if _, ok := m.live(k, now); ok {
+				out = append(out, k)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+func expiry(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
@@ -0,0 +1,101 @@
+//go:build redis
+
+package state
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis é um Store compartilhado entre réplicas.
+type Redis struct {
+	rdb    *redis.Client
+	prefix string
+}
+
+// NewFromEnv usa REDIS_URL; sem ele (ou com URL inválida) cai para memória.
+func NewFromEnv() Store {
+	u := strings.TrimSpace(os.Getenv("REDIS_URL"))
+	if u == "" {
+		return NewMemory()
+	}
+	opt, err := redis.ParseURL(u)
+	if err != nil {
+		return NewMemory()
+	}
+	return &Redis{rdb: redis.NewClient(opt), prefix: "paclead:"}
+}
+
+func (r *Redis) Get(ctx context.Context, key string, out any) (bool, error) {
+	b, err := r.rdb.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(b, out)
+}
+
+func (r *Redis) Set(ctx context.Context, key string, v any, ttl time.Duration) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return r.rdb.Set(ctx, r.prefix+key, b, ttl).Err()
+}
+
+func (r *Redis) Delete(ctx context.Context, key string) error {
+	return r.rdb.Del(ctx, r.prefix+key).Err()
+}
+
+func (r *Redis) SetNX(ctx context.Context, key string, v any, ttl time.Duration) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	return r.rdb.SetNX(ctx, r.prefix+key, b, ttl).Result()
+}
+
+// deleteIf compara e apaga atomicamente no servidor.
+var deleteIf = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then return redis.call("DEL", KEYS[1]) end return 0`)
+
+func (r *Redis) DeleteIf(ctx context.Context, key string, v any) (bool, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return false, err
+	}
+	n, err := deleteIf.Run(ctx, r.rdb, []string{r.prefix + key}, b).Int()
+	return n == 1, err
+}
+
+// O valor fica como número puro, que também é JSON válido para Get.
+func (r *Redis) Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.rdb.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		incr = p.IncrBy(ctx, r.prefix+key, delta)
+		if ttl > 0 {
+			p.Expire(ctx, r.prefix+key, ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (r *Redis) Keys(ctx context.Context, prefix string) ([]string, error) {
+	var out []string
+	iter := r.rdb.Scan(ctx, 0, r.prefix+prefix+"*", 200).Iterator()
+	for iter.Next(ctx) {
+		out = append(out, strings.TrimPrefix(iter.Val(), r.prefix))
+	}
+	return out, iter.Err()
+}
@@ -0,0 +1,39 @@
+// Package state guarda estado de conversa (paginação, carrinho, agendamentos)
+// em um KV com TTL. Sem a tag de build "redis" usa memória local; com ela e
+// REDIS_URL definido, usa Redis (necessário com múltiplas réplicas).
+package state
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store é um KV de valores JSON com expiração.
+type Store interface {
+	// Get decodifica o valor em out; retorna false se a chave não existir.
+	Get(ctx context.Context, key string, out any) (bool, error)
+	Set(ctx context.Context, key string, v any, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	// SetNX grava apenas se a chave não existir (locks/leases).
+	SetNX(ctx context.Context, key string, v any, ttl time.Duration) (bool, error)
+	// DeleteIf apaga a chave só se o valor atual for v (liberar lock/lease do
+	// próprio dono); retorna false se o valor mudou ou a chave não existe.
+	DeleteIf(ctx context.Context, key string, v any) (bool, error)
+	// Incr soma delta ao inteiro da chave (criada com 0) de forma atômica,
+	// renova o TTL e devolve o novo valor.
+	Incr(ctx context.Context, key string, delta int64, ttl time.Duration) (int64, error)
+	// Keys lista as chaves com o prefixo informado.
+	Keys(ctx context.Context, prefix string) ([]string, error)
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore Store
+)
+
+// Default devolve o Store do processo, criado a partir do ambiente na primeira chamada.
+func Default() Store {
+	defaultOnce.Do(func() { defaultStore = NewFromEnv() })
+	return defaultStore
+}
@@ -0,0 +1,6 @@
+//go:build postgres
+
+package transcript
+
+// Driver Postgres, registrado como "pgx".
+import _ "github.com/jackc/pgx/v5/stdlib"
@@ -0,0 +1,6 @@
+//go:build sqlite
+
+package transcript
+
+// Driver SQLite em Go puro (sem cgo), registrado como "sqlite".
+import _ "modernc.org/sqlite"
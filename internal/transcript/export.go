@@ -0,0 +1,61 @@
+package transcript
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+)
+
+// exportPage é o tamanho de cada página lida do Store durante a exportação.
+const exportPage = 500
+
+// Each percorre todas as mensagens da consulta, página a página, ignorando
+// q.Limit/q.Offset.
+func Each(ctx context.Context, st Store, q Query, fn func(Message) error) error {
+	q.Limit, q.Offset = exportPage, 0
+	for {
+		msgs, err := st.List(ctx, q)
+		if err != nil {
+			return err
+		}
+		for _, m := range msgs {
+			if err := fn(m); err != nil {
+				return err
+			}
+		}
+		if len(msgs) < exportPage {
+			return nil
+		}
+		q.Offset += len(msgs)
+	}
+}
+
+var csvHeader = []string{"id", "tenant", "org_id", "flow_id", "number", "direction", "type", "text", "media",
+	"message_id", "run_id", "status", "error", "at", "updated_at"}
+
+// WriteCSV exporta as mensagens da consulta em CSV (com cabeçalho).
+func WriteCSV(ctx context.Context, w io.Writer, st Store, q Query) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	err := Each(ctx, st, q, func(m Message) error {
+		return cw.Write([]string{strconv.FormatInt(m.ID, 10), m.Tenant, m.OrgID, m.FlowID, m.Number, m.Direction,
+			m.Type, m.Text, m.Media, m.MessageID, m.RunID, m.Status, m.Error,
+			m.At.Format(time.RFC3339), m.UpdatedAt.Format(time.RFC3339)})
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+// WriteJSONL exporta as mensagens da consulta em JSON Lines.
+func WriteJSONL(ctx context.Context, w io.Writer, st Store, q Query) error {
+	enc := json.NewEncoder(w)
+	return Each(ctx, st, q, func(m Message) error { return enc.Encode(m) })
+}
@@ -0,0 +1,148 @@
+package transcript
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Memory guarda as últimas mensagens em processo (descarta as mais antigas ao
+// passar de max). Adequado para uma única réplica e para desenvolvimento.
+type Memory struct {
+	mu     sync.Mutex
+	max    int
+	nextID int64
+	msgs   []Message
+}
+
+func NewMemory(max int) *Memory { return &Memory{max: max} }
+
+func (m *Memory) Append(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nextID++
+	msg.ID = m.nextID
+	if msg.At.IsZero() {
+		msg.At = time.Now().UTC()
+	}
+	msg.UpdatedAt = msg.At
+	m.msgs = append(m.msgs, *msg)
+	if m.max > 0 && len(m.msgs) > m.max {
+		m.msgs = append([]Message(nil), m.msgs[len(m.msgs)-m.max:]...)
+	}
+	return nil
+}
+
+func (m *Memory) SetStatus(ctx context.Context, messageID, status string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := range m.msgs {
+		msg := &m.msgs[i]
+		if msg.MessageID == messageID && msg.Direction == Outbound && statusRank[status] > statusRank[msg.Status] {
+			msg.Status, msg.UpdatedAt = status, time.Now().UTC()
+		}
+	}
+	return nil
+}
+
+// match aplica os filtros da consulta (exceto paginação).
+func (q Query) match(m Message) bool {
+	return (q.Tenant == "" || m.Tenant == q.Tenant) &&
+		(q.OrgID == "" || m.OrgID == q.OrgID) &&
+		(q.Number == "" || m.Number == q.Number) &&
+		(q.Direction == "" || m.Direction == q.Direction) &&
+		(q.Since.IsZero() || !m.At.Before(q.Since)) &&
+		(q.Until.IsZero() || m.At.Before(q.Until))
+}
+
+func (m *Memory) filter(q Query) []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var out []Message
+	for _, msg := range m.msgs {
+		if q.match(msg) {
+			out = append(out, msg)
+		}
+	}
+	return out
+}
+
+// bounds devolve o intervalo [lo, hi) de uma lista de n itens após offset/limit.
+func (q Query) bounds(n int) (int, int) {
+	lo := q.Offset
+	if lo < 0 {
+		lo = 0
+	}
+	if lo > n {
+		lo = n
+	}
+	hi := lo + q.limit()
+	if hi > n {
+		hi = n
+	}
+	return lo, hi
+}
+
+func (m *Memory) List(ctx context.Context, q Query) ([]Message, error) {
+	out := m.filter(q)
+	lo, hi := q.bounds(len(out))
+	return out[lo:hi], nil
+}
+
+func (m *Memory) Contacts(ctx context.Context, q Query) ([]Contact, error) {
+	idx := map[string]int{}
+	var out []Contact
+	for _, msg := range m.filter(q) {
+		k := msg.Tenant + ":" + msg.Number
+		i, ok := idx[k]
+		if !ok {
+			i = len(out)
+			idx[k] = i
+			out = append(out, Contact{Tenant: msg.Tenant, Number: msg.Number, FirstAt: msg.At})
+		}
+		c := &out[i]
+		if msg.Direction == Inbound {
+			c.Inbound++
+		} else {
+			c.Outbound++
+		}
+		c.LastAt = msg.At
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].LastAt.After(out[j].LastAt) })
+	lo, hi := q.bounds(len(out))
+	return out[lo:hi], nil
+}
+
+func (m *Memory) Stats(ctx context.Context, q Query) (Stats, error) {
+	st := newStats()
+	contacts := map[string]bool{}
+	for _, msg := range m.filter(q) {
+		st.add(msg.Direction, msg.Type, msg.Status, 1)
+		contacts[msg.Tenant+":"+msg.Number] = true
+	}
+	st.Contacts = len(contacts)
+	return st, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, tenant, number string) (int, error) {
+	return m.remove(func(msg Message) bool { return msg.Tenant == tenant && msg.Number == number }), nil
+}
+
+func (m *Memory) Purge(ctx context.Context, before time.Time) (int, error) {
+	return m.remove(func(msg Message) bool { return msg.At.Before(before) }), nil
+}
+
+func (m *Memory) remove(drop func(Message) bool) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	kept := m.msgs[:0]
+	for _, msg := range m.msgs {
+		if !drop(msg) {
+			kept = append(kept, msg)
+		}
+	}
+	n := len(m.msgs) - len(kept)
+	m.msgs = kept
+	return n
+}
@@ -0,0 +1,263 @@
+package transcript
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SQL é o Store em banco relacional via database/sql. Atende SQLite (driver
+// "sqlite", Go puro) e Postgres (driver "pgx"); os drivers entram pelas tags
+// de build "sqlite" e "postgres". Datas são gravadas em milissegundos Unix
+// para o mesmo esquema servir aos dois bancos.
+type SQL struct {
+	db       *sql.DB
+	postgres bool
+}
+
+// OpenSQL abre o banco e cria a tabela e os índices se necessário.
+func OpenSQL(ctx context.Context, driver, dsn string) (*SQL, error) {
+	if strings.TrimSpace(dsn) == "" {
+		return nil, fmt.Errorf("transcript: DSN vazio")
+	}
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	s := &SQL{db: db, postgres: driver != "sqlite"}
+	if !s.postgres {
+		// SQLite aceita um escritor por vez
+		db.SetMaxOpenConns(1)
+	}
+	if err := s.migrate(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *SQL) migrate(ctx context.Context) error {
+	id := "INTEGER PRIMARY KEY AUTOINCREMENT"
+	if s.postgres {
+		id = "BIGSERIAL PRIMARY KEY"
+	}
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS transcript_messages (
+			id ` + id + `,
+			tenant TEXT NOT NULL,
+			org_id TEXT NOT NULL DEFAULT '',
+			flow_id TEXT NOT NULL DEFAULT '',
+			number TEXT NOT NULL,
+			direction TEXT NOT NULL,
+			type TEXT NOT NULL DEFAULT '',
+			text TEXT NOT NULL DEFAULT '',
+			media TEXT NOT NULL DEFAULT '',
+			message_id TEXT NOT NULL DEFAULT '',
+			run_id TEXT NOT NULL DEFAULT '',
+			status TEXT NOT NULL DEFAULT '',
+			error TEXT NOT NULL DEFAULT '',
+			at_ms BIGINT NOT NULL,
+			updated_ms BIGINT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS transcript_contact_idx ON transcript_messages (tenant, number, at_ms)`,
+		`CREATE INDEX IF NOT EXISTS transcript_at_idx ON transcript_messages (at_ms)`,
+		`CREATE INDEX IF NOT EXISTS transcript_message_id_idx ON transcript_messages (message_id)`,
+	}
+	for _, q := range stmts {
+		if _, err := s.db.ExecContext(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebind troca os "?" pelos "$n" do Postgres.
+func (s *SQL) rebind(q string) string {
+	if !s.postgres {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func ms(t time.Time) int64 { return t.UnixNano() / int64(time.Millisecond) }
+
+func fromMS(v int64) time.Time { return time.Unix(0, v*int64(time.Millisecond)).UTC() }
+
+func (s *SQL) Append(ctx context.Context, m *Message) error {
+	if m.At.IsZero() {
+		m.At = time.Now().UTC()
+	}
+	m.UpdatedAt = m.At
+	q := `INSERT INTO transcript_messages
+		(tenant, org_id, flow_id, number, direction, type, text, media, message_id, run_id, status, error, at_ms, updated_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+	args := []any{m.Tenant, m.OrgID, m.FlowID, m.Number, m.Direction, m.Type, m.Text, m.Media,
+		m.MessageID, m.RunID, m.Status, m.Error, ms(m.At), ms(m.UpdatedAt)}
+	if s.postgres {
+		// pgx não implementa LastInsertId
+		return s.db.QueryRowContext(ctx, s.rebind(q+" RETURNING id"), args...).Scan(&m.ID)
+	}
+	res, err := s.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return err
+	}
+	m.ID, err = res.LastInsertId()
+	return err
+}
+
+func (s *SQL) SetStatus(ctx context.Context, messageID, status string) error {
+	rank, ok := statusRank[status]
+	if !ok || messageID == "" {
+		return nil
+	}
+	// só avança: status com rank maior ou igual não são sobrescritos
+	args := []any{status, ms(time.Now()), messageID, Outbound}
+	var keep []string
+	for st, r := range statusRank {
+		if r >= rank {
+			keep = append(keep, "?")
+			args = append(args, st)
+		}
+	}
+	q := `UPDATE transcript_messages SET status = ?, updated_ms = ?
+		WHERE message_id = ? AND direction = ? AND status NOT IN (` + strings.Join(keep, ", ") + `)`
+	_, err := s.db.ExecContext(ctx, s.rebind(q), args...)
+	return err
+}
+
+// where monta o filtro da consulta.
+func (q Query) where() (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, v any) {
+		conds = append(conds, cond)
+		args = append(args, v)
+	}
+	if q.Tenant != "" {
+		add("tenant = ?", q.Tenant)
+	}
+	if q.OrgID != "" {
+		add("org_id = ?", q.OrgID)
+	}
+	if q.Number != "" {
+		add("number = ?", q.Number)
+	}
+	if q.Direction != "" {
+		add("direction = ?", q.Direction)
+	}
+	if !q.Since.IsZero() {
+		add("at_ms >= ?", ms(q.Since))
+	}
+	if !q.Until.IsZero() {
+		add("at_ms < ?", ms(q.Until))
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (s *SQL) List(ctx context.Context, q Query) ([]Message, error) {
+	where, args := q.where()
+	args = append(args, q.limit(), q.Offset)
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT id, tenant, org_id, flow_id, number, direction, type, text, media,
+		message_id, run_id, status, error, at_ms, updated_ms FROM transcript_messages`+where+
+		` ORDER BY at_ms, id LIMIT ? OFFSET ?`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Message
+	for rows.Next() {
+		var m Message
+		var at, upd int64
+		if err := rows.Scan(&m.ID, &m.Tenant, &m.OrgID, &m.FlowID, &m.Number, &m.Direction, &m.Type, &m.Text, &m.Media,
+			&m.MessageID, &m.RunID, &m.Status, &m.Error, &at, &upd); err != nil {
+			return nil, err
+		}
+		m.At, m.UpdatedAt = fromMS(at), fromMS(upd)
+		out = append(out, m)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQL) Contacts(ctx context.Context, q Query) ([]Contact, error) {
+	where, args := q.where()
+	args = append(args, q.limit(), q.Offset)
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT tenant, number,
+		SUM(CASE WHEN direction = '`+Inbound+`' THEN 1 ELSE 0 END),
+		SUM(CASE WHEN direction = '`+Outbound+`' THEN 1 ELSE 0 END),
+		MIN(at_ms), MAX(at_ms)
+		FROM transcript_messages`+where+`
+		GROUP BY tenant, number ORDER BY MAX(at_ms) DESC LIMIT ? OFFSET ?`), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var out []Contact
+	for rows.Next() {
+		var c Contact
+		var first, last int64
+		if err := rows.Scan(&c.Tenant, &c.Number, &c.Inbound, &c.Outbound, &first, &last); err != nil {
+			return nil, err
+		}
+		c.FirstAt, c.LastAt = fromMS(first), fromMS(last)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+func (s *SQL) Stats(ctx context.Context, q Query) (Stats, error) {
+	st := newStats()
+	where, args := q.where()
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT direction, type, status, COUNT(*)
+		FROM transcript_messages`+where+` GROUP BY direction, type, status`), args...)
+	if err != nil {
+		return st, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var dir, typ, status string
+		var n int
+		if err := rows.Scan(&dir, &typ, &status, &n); err != nil {
+			return st, err
+		}
+		st.add(dir, typ, status, n)
+	}
+	if err := rows.Err(); err != nil {
+		return st, err
+	}
+	err = s.db.QueryRowContext(ctx, s.rebind(`SELECT COUNT(*) FROM
+		(SELECT DISTINCT tenant, number FROM transcript_messages`+where+`) t`), args...).Scan(&st.Contacts)
+	return st, err
+}
+
+func (s *SQL) Delete(ctx context.Context, tenant, number string) (int, error) {
+	return s.exec(ctx, `DELETE FROM transcript_messages WHERE tenant = ? AND number = ?`, tenant, number)
+}
+
+func (s *SQL) Purge(ctx context.Context, before time.Time) (int, error) {
+	return s.exec(ctx, `DELETE FROM transcript_messages WHERE at_ms < ?`, ms(before))
+}
+
+func (s *SQL) exec(ctx context.Context, q string, args ...any) (int, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(q), args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
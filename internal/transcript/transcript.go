@@ -0,0 +1,237 @@
+// Package transcript guarda a transcrição das conversas (mensagens recebidas e
+// enviadas) fora das threads da OpenAI, para consulta, auditoria, exportação e
+// métricas. Sem configuração usa memória local; com as tags de build "sqlite"
+// (Go puro, sem cgo) ou "postgres" e TRANSCRIPT_STORE definido, usa SQL.
+package transcript
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Direções da mensagem.
+const (
+	Inbound  = "in"
+	Outbound = "out"
+)
+
+// Status de entrega. Recebidas ficam "received"; enviadas começam em "sent"
+// (ou "failed") e avançam com os recibos do gateway.
+const (
+	StatusReceived  = "received"
+	StatusFailed    = "failed"
+	StatusSent      = "sent"
+	StatusDelivered = "delivered"
+	StatusRead      = "read"
+)
+
+// statusRank ordena os status de envio: um recibo nunca rebaixa a mensagem
+// (um "delivered" atrasado não desfaz o "read").
+var statusRank = map[string]int{
+	StatusFailed:    1,
+	StatusSent:      2,
+	StatusDelivered: 3,
+	StatusRead:      4,
+}
+
+// NormalizeStatus traduz o status de entrega do gateway (Uazapi, Evolution,
+// Cloud API) para os status da transcrição; vazio se não reconhecido.
+func NormalizeStatus(s string) string {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "sent", "server_ack", "serverack", "1":
+		return StatusSent
+	case "delivered", "delivery_ack", "deliveryack", "2":
+		return StatusDelivered
+	case "read", "read_ack", "readack", "played", "3", "4":
+		return StatusRead
+	case "failed", "error":
+		return StatusFailed
+	}
+	return ""
+}
+
+// Message é uma mensagem da conversa.
+type Message struct {
+	ID int64 `json:"id"`
+	// Tenant é o CNPJ da empresa; OrgID/FlowID são as opções de origem.
+	Tenant    string `json:"tenant"`
+	OrgID     string `json:"org_id,omitempty"`
+	FlowID    string `json:"flow_id,omitempty"`
+	Number    string `json:"number"`
+	Direction string `json:"direction"`
+	// Type é o tipo da mensagem (text, audio, image, menu, carousel...).
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// Media é a URL do arquivo enviado/recebido, quando houver.
+	Media string `json:"media,omitempty"`
+	// MessageID é o id da mensagem no gateway (recibos de entrega).
+	MessageID string `json:"message_id,omitempty"`
+	// RunID é o run do assistente que gerou a resposta.
+	RunID     string    `json:"run_id,omitempty"`
+	Status    string    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+	At        time.Time `json:"at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Query filtra mensagens; campos vazios não filtram.
+type Query struct {
+	Tenant    string
+	OrgID     string
+	Number    string
+	Direction string
+	Since     time.Time
+	Until     time.Time
+	// Limit 0 usa o padrão (200); Offset pula as primeiras (mais antigas).
+	Limit  int
+	Offset int
+}
+
+func (q Query) limit() int {
+	if q.Limit <= 0 {
+		return 200
+	}
+	return q.Limit
+}
+
+// Contact resume as mensagens de um número (lista de conversas).
+type Contact struct {
+	Tenant   string    `json:"tenant"`
+	Number   string    `json:"number"`
+	Inbound  int       `json:"inbound"`
+	Outbound int       `json:"outbound"`
+	FirstAt  time.Time `json:"first_at"`
+	LastAt   time.Time `json:"last_at"`
+}
+
+// Stats são as métricas agregadas de um período.
+type Stats struct {
+	Inbound  int            `json:"inbound"`
+	Outbound int            `json:"outbound"`
+	Contacts int            `json:"contacts"`
+	ByType   map[string]int `json:"by_type"`
+	ByStatus map[string]int `json:"by_status"`
+}
+
+func (s *Stats) add(direction, typ, status string, n int) {
+	if direction == Inbound {
+		s.Inbound += n
+	} else {
+		s.Outbound += n
+	}
+	s.ByType[typ] += n
+	s.ByStatus[status] += n
+}
+
+func newStats() Stats {
+	return Stats{ByType: map[string]int{}, ByStatus: map[string]int{}}
+}
+
+// Store persiste as transcrições.
+type Store interface {
+	// Append grava a mensagem e preenche ID (e At, se vazio).
+	Append(ctx context.Context, m *Message) error
+	// SetStatus aplica um recibo do gateway à mensagem enviada com o id informado.
+	SetStatus(ctx context.Context, messageID, status string) error
+	// List devolve as mensagens em ordem cronológica.
+	List(ctx context.Context, q Query) ([]Message, error)
+	// Contacts lista os números com mensagens, do mais recente ao mais antigo.
+	Contacts(ctx context.Context, q Query) ([]Contact, error)
+	Stats(ctx context.Context, q Query) (Stats, error)
+	// Delete apaga as mensagens de um número (eliminação LGPD).
+	Delete(ctx context.Context, tenant, number string) (int, error)
+	// Purge apaga as mensagens anteriores a before (retenção).
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+var (
+	defaultOnce  sync.Once
+	defaultStore Store
+	defaultErr   error
+)
+
+// Init abre o Store do processo conforme o ambiente:
+//
+//	TRANSCRIPT_STORE       memory (padrão), sqlite, postgres ou off
+//	TRANSCRIPT_DSN         arquivo do SQLite (padrão: transcripts.db) ou DSN do
+//	                       Postgres (padrão: DATABASE_URL)
+//	TRANSCRIPT_MEMORY_MAX  mensagens mantidas em memória (padrão 10000)
+//	TRANSCRIPT_RETENTION_DAYS  dias de retenção (padrão 0 = sem expurgo; ver Retention)
+//
+// sqlite e postgres exigem o binário compilado com a tag correspondente. Deve
+// ser chamado na subida: um banco pedido e indisponível é erro (não cai para
+// memória, o que perderia as transcrições sem ninguém perceber).
+func Init() error {
+	defaultOnce.Do(func() { defaultStore, defaultErr = NewFromEnv() })
+	return defaultErr
+}
+
+// Default devolve o Store do processo (ver Init). Encerra o processo se o
+// Store configurado não abriu.
+func Default() Store {
+	if err := Init(); err != nil {
+		log.Fatalln(err)
+	}
+	return defaultStore
+}
+
+// NewFromEnv cria o Store descrito em Init.
+func NewFromEnv() (Store, error) {
+	max := 10000
+	if n, err := strconv.Atoi(os.Getenv("TRANSCRIPT_MEMORY_MAX")); err == nil && n > 0 {
+		max = n
+	}
+	dsn := strings.TrimSpace(os.Getenv("TRANSCRIPT_DSN"))
+	kind := strings.ToLower(strings.TrimSpace(os.Getenv("TRANSCRIPT_STORE")))
+	switch kind {
+	case "", "memory":
+		return NewMemory(max), nil
+	case "off", "none", "false":
+		return Nop{}, nil
+	case "sqlite":
+		if dsn == "" {
+			dsn = "transcripts.db"
+		}
+		s, err := OpenSQL(context.Background(), "sqlite", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("transcript: TRANSCRIPT_STORE=sqlite (binário com -tags sqlite?): %w", err)
+		}
+		return s, nil
+	case "postgres", "postgresql":
+		if dsn == "" {
+			dsn = os.Getenv("DATABASE_URL")
+		}
+		s, err := OpenSQL(context.Background(), "pgx", dsn)
+		if err != nil {
+			return nil, fmt.Errorf("transcript: TRANSCRIPT_STORE=postgres (binário com -tags postgres?): %w", err)
+		}
+		return s, nil
+	}
+	return nil, fmt.Errorf("transcript: TRANSCRIPT_STORE desconhecido: %q", kind)
+}
+
+// Retention devolve o prazo de guarda das transcrições
+// (TRANSCRIPT_RETENTION_DAYS); 0 mantém tudo.
+func Retention() time.Duration {
+	if d, err := strconv.Atoi(os.Getenv("TRANSCRIPT_RETENTION_DAYS")); err == nil && d > 0 {
+		return time.Duration(d) * 24 * time.Hour
+	}
+	return 0
+}
+
+// Nop descarta as transcrições (TRANSCRIPT_STORE=off).
+type Nop struct{}
+
+func (Nop) Append(context.Context, *Message) error              { return nil }
+func (Nop) SetStatus(context.Context, string, string) error     { return nil }
+func (Nop) List(context.Context, Query) ([]Message, error)      { return nil, nil }
+func (Nop) Contacts(context.Context, Query) ([]Contact, error)  { return nil, nil }
+func (Nop) Stats(context.Context, Query) (Stats, error)         { return newStats(), nil }
+func (Nop) Delete(context.Context, string, string) (int, error) { return 0, nil }
+func (Nop) Purge(context.Context, time.Time) (int, error)       { return 0, nil }
@@ -0,0 +1,213 @@
+package ttscache
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Memory é o backend local do processo (TTL + limite de itens).
+type Memory struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	max     int
+	entries map[string]memEntry
+}
+
+type memEntry struct {
+	audio   []byte
+	expires time.Time
+}
+
+func NewMemory(ttl time.Duration, maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = 256
+	}
+	return &Memory{ttl: ttl, max: maxEntries, entries: map[string]memEntry{}}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(e.expires) {
+		delete(m.entries, key)
+		return nil, false
+	}
+	return e.audio, true
+}
+
+func (m *Memory) Set(_ context.Context, key string, audio []byte) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if _, ok := m.entries[key]; !ok && len(m.entries) >= m.max {
+		// remove os vencidos; se ainda cheio, o que vence primeiro
+		var first string
+		for k, e := range m.entries {
+			if now.After(e.expires) {
+				delete(m.entries, k)
+			} else if first == "" || e.expires.Before(m.entries[first].expires) {
+				first = k
+			}
+		}
+		if len(m.entries) >= m.max {
+			delete(m.entries, first)
+		}
+	}
+	m.entries[key] = memEntry{audio: audio, expires: now.Add(m.ttl)}
+}
+
+// Disk guarda um arquivo por áudio; a validade é contada pelo mtime. Os
+// arquivos vencidos são apagados e, acima de MaxBytes, os mais antigos saem
+// primeiro (varredura na criação e a cada pruneEvery, disparada por Set).
+type Disk struct {
+	Dir      string
+	TTL      time.Duration
+	MaxBytes int64
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+// pruneEvery espaça as varreduras do diretório.
+const pruneEvery = 10 * time.Minute
+
+func NewDisk(dir string, ttl time.Duration, maxBytes int64) *Disk {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Println("ttscache: disk:", err)
+	}
+	d := &Disk{Dir: dir, TTL: ttl, MaxBytes: maxBytes}
+	d.prune()
+	return d
+}
+
+func (d *Disk) path(key string) string {
+	return filepath.Join(d.Dir, key+".audio")
+}
+
+func (d *Disk) Get(_ context.Context, key string) ([]byte, bool) {
+	p := d.path(key)
+	fi, err := os.Stat(p)
+	if err != nil {
+		return nil, false
+	}
+	if d.TTL > 0 && time.Since(fi.ModTime()) > d.TTL {
+		_ = os.Remove(p)
+		return nil, false
+	}
+	b, err := os.ReadFile(p)
+	if err != nil || len(b) == 0 {
+		return nil, false
+	}
+	return b, true
+}
+
+// Set grava em arquivo temporário e renomeia, para leitores nunca verem um
+// áudio pela metade.
+func (d *Disk) Set(_ context.Context, key string, audio []byte) {
+	tmp, err := os.CreateTemp(d.Dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, werr := tmp.Write(audio)
+	cerr := tmp.Close()
+	if werr != nil || cerr != nil {
+		_ = os.Remove(tmp.Name())
+		return
+	}
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	d.mu.Lock()
+	due := time.Since(d.lastPrune) >= pruneEvery
+	d.mu.Unlock()
+	if due {
+		d.prune()
+	}
+}
+
+// prune apaga áudios vencidos, temporários abandonados e, se o diretório
+// passar de MaxBytes, os áudios mais antigos até caber.
+func (d *Disk) prune() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastPrune = time.Now()
+	entries, err := os.ReadDir(d.Dir)
+	if err != nil {
+		return
+	}
+	type file struct {
+		path string
+		size int64
+		mod  time.Time
+	}
+	var (
+		files []file
+		total int64
+	)
+	for _, e := range entries {
+		fi, err := e.Info()
+		if err != nil || !fi.Mode().IsRegular() {
+			continue
+		}
+		p := filepath.Join(d.Dir, e.Name())
+		age := time.Since(fi.ModTime())
+		switch {
+		case strings.HasSuffix(e.Name(), ".tmp"):
+			if age > time.Hour {
+				_ = os.Remove(p)
+			}
+			continue
+		case !strings.HasSuffix(e.Name(), ".audio"):
+			continue
+		case d.TTL > 0 && age > d.TTL:
+			_ = os.Remove(p)
+			continue
+		}
+		files = append(files, file{p, fi.Size(), fi.ModTime()})
+		total += fi.Size()
+	}
+	if d.MaxBytes <= 0 || total <= d.MaxBytes {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].mod.Before(files[j].mod) })
+	for _, f := range files {
+		if total <= d.MaxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
+
+// Store usa o state.Store (Redis com a tag "redis"), compartilhado entre réplicas.
+type Store struct {
+	st  state.Store
+	ttl time.Duration
+}
+
+func NewStore(st state.Store, ttl time.Duration) *Store {
+	return &Store{st: st, ttl: ttl}
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, bool) {
+	var b []byte
+	ok, err := s.st.Get(ctx, "tts:"+key, &b)
+	return b, ok && err == nil && len(b) > 0
+}
+
+func (s *Store) Set(ctx context.Context, key string, audio []byte) {
+	_ = s.st.Set(ctx, "tts:"+key, audio, s.ttl)
+}
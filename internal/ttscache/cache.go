@@ -0,0 +1,114 @@
+// Package ttscache guarda áudios já sintetizados, endereçados pelo conteúdo
+// (texto + modelo + voz + instruções + formato). Saudações e respostas
+// repetidas deixam de chamar o /audio/speech de novo.
+package ttscache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"pac-lead-agent/internal/state"
+)
+
+// Cache é um backend de áudio sintetizado.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, audio []byte)
+}
+
+// Key endereça o áudio pelo conteúdo: qualquer mudança de texto, voz, modelo,
+// estilo ou formato gera outra chave.
+func Key(text, model, voice, instructions, format string) string {
+	h := sha256.New()
+	for _, p := range []string{model, voice, instructions, format, text} {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Tiered consulta os caches em ordem e preenche os mais rápidos no acerto.
+type Tiered []Cache
+
+func (t Tiered) Get(ctx context.Context, key string) ([]byte, bool) {
+	for i, c := range t {
+		if b, ok := c.Get(ctx, key); ok {
+			for _, up := range t[:i] {
+				up.Set(ctx, key, b)
+			}
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (t Tiered) Set(ctx context.Context, key string, audio []byte) {
+	for _, c := range t {
+		c.Set(ctx, key, audio)
+	}
+}
+
+// Nop não guarda nada (TTS_CACHE=off).
+type Nop struct{}
+
+func (Nop) Get(context.Context, string) ([]byte, bool) { return nil, false }
+func (Nop) Set(context.Context, string, []byte)        {}
+
+var (
+	defaultOnce  sync.Once
+	defaultCache Cache
+)
+
+// Default devolve o cache do processo conforme o ambiente:
+//
+//	TTS_CACHE           memory (padrão), disk, redis ou off
+//	TTS_CACHE_DIR       diretório do backend disk (padrão: <tmp>/paclead-tts)
+//	TTS_CACHE_TTL_HOURS validade dos áudios (padrão 720 = 30 dias)
+//	TTS_CACHE_DISK_MB   tamanho máximo do backend disk (padrão 512)
+//
+// disk e redis ficam atrás de um cache em memória. "redis" usa o state.Store
+// do processo (Redis quando compilado com a tag "redis" e REDIS_URL definido);
+// sem Redis fica só o cache em memória, que tem limite de itens (o state.Store
+// em memória não tem).
+func Default() Cache {
+	defaultOnce.Do(func() { defaultCache = NewFromEnv() })
+	return defaultCache
+}
+
+func NewFromEnv() Cache {
+	ttl := 720 * time.Hour
+	if h, err := strconv.Atoi(os.Getenv("TTS_CACHE_TTL_HOURS")); err == nil && h > 0 {
+		ttl = time.Duration(h) * time.Hour
+	}
+	mem := NewMemory(ttl, 256)
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("TTS_CACHE"))) {
+	case "off", "none", "false":
+		return Nop{}
+	case "disk":
+		dir := strings.TrimSpace(os.Getenv("TTS_CACHE_DIR"))
+		if dir == "" {
+			dir = filepath.Join(os.TempDir(), "paclead-tts")
+		}
+		maxMB := 512
+		if n, err := strconv.Atoi(os.Getenv("TTS_CACHE_DISK_MB")); err == nil && n > 0 {
+			maxMB = n
+		}
+		return Tiered{mem, NewDisk(dir, ttl, int64(maxMB)<<20)}
+	case "redis":
+		st := state.Default()
+		if _, local := st.(*state.Memory); local {
+			log.Println("ttscache: TTS_CACHE=redis sem Redis (tag redis e REDIS_URL); usando só memória")
+			return mem
+		}
+		return Tiered{mem, NewStore(st, ttl)}
+	}
+	return mem
+}
@@ -18,9 +18,17 @@ type IncomingBody struct {
 }
 
 type Message struct {
+	// ID é o id da mensagem no gateway (usado na confirmação de leitura)
+	ID      string `json:"messageid,omitempty"`
 	ChatID  string `json:"chatId"`
 	Type    string `json:"type"`
 	Content string `json:"content"`
+	// ButtonID é o id do botão/linha de lista tocado (respostas interativas)
+	ButtonID string `json:"buttonOrListid,omitempty"`
+	// SenderName é o nome de perfil do contato (pushName)
+	SenderName string `json:"senderName,omitempty"`
+	// Status é o recibo de entrega nos eventos de atualização (messages_update)
+	Status string `json:"status,omitempty"`
 	// Campos adicionais ignorados
 }
 
@@ -60,9 +68,45 @@ func (m *Message) UnmarshalJSON(data []byte) error {
 	if m.Type == "" {
 		m.Type = str(raw["type"])
 	}
+	for _, k := range []string{"messageid", "messageId", "id"} {
+		if m.ID != "" {
+			break
+		}
+		m.ID = str(raw[k])
+	}
 	if m.Content == "" {
 		m.Content = str(raw["content"])
 	}
+	for _, k := range []string{"senderName", "pushName", "notifyName"} {
+		if m.SenderName != "" {
+			break
+		}
+		m.SenderName = str(raw[k])
+	}
+	for _, k := range []string{"status", "messageStatus", "ack"} {
+		if m.Status != "" {
+			break
+		}
+		m.Status = str(raw[k])
+	}
+	for _, k := range []string{"buttonOrListid", "selectedButtonId", "selectedId", "selectedRowId", "buttonId"} {
+		if m.ButtonID != "" {
+			break
+		}
+		m.ButtonID = str(raw[k])
+	}
+	// Alguns gateways mandam a resposta de botão como objeto em "content"
+	if obj, ok := raw["content"].(map[string]any); ok {
+		if m.ButtonID == "" {
+			m.ButtonID = str(obj["selectedButtonId"])
+		}
+		if m.ButtonID == "" {
+			m.ButtonID = str(obj["selectedId"])
+		}
+		if m.Content == "" {
+			m.Content = str(obj["selectedDisplayText"])
+		}
+	}
 	return nil
 }
 
@@ -77,4 +121,8 @@ type LeadRecord struct {
 	DataUltMsg string `json:"data_ult_msg"`
 	UltMsgNum  string `json:"ult_msg_numero"`
 	CNPJCPF    string `json:"cnpj_cpf"`
+	// Campos de acompanhamento (opcionais no /leadpost)
+	UltMsgBot    string `json:"ult_msg_bot,omitempty"`
+	MsgRecebidas int    `json:"qtd_msg_recebidas,omitempty"`
+	MsgEnviadas  int    `json:"qtd_msg_enviadas,omitempty"`
 }
@@ -0,0 +1,339 @@
+package whatsfmt
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Marcadores internos (fora do texto normal) usados durante a conversão.
+const (
+	markBold   = "\x01"
+	markItalic = "\x02"
+	holdOpen   = "\x03"
+	holdClose  = "\x04"
+)
+
+var (
+	fence      = regexp.MustCompile("^\\s*(```|~~~)")
+	heading    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+(.*?)\s*#*\s*$`)
+	setextRule = regexp.MustCompile(`^\s{0,3}(=+|-+)\s*$`)
+	hrule      = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	bullet     = regexp.MustCompile(`^(\s*)[*+•]\s+`)
+	quote      = regexp.MustCompile(`^\s{0,3}>\s?`)
+	tableSep   = regexp.MustCompile(`^:?-{2,}:?$`)
+
+	inlineCode = regexp.MustCompile("`[^`\n]+`")
+	escaped    = regexp.MustCompile(`\\([\\*_~` + "`" + `#\[\]()>+.!|-])`)
+	image      = regexp.MustCompile(`!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	link       = regexp.MustCompile(`\[([^\]]+)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)`)
+	autolink   = regexp.MustCompile(`<((?:https?|mailto):[^>\s]+)>`)
+	bareURL    = regexp.MustCompile(`(?:https?://|www\.)[^\s<>()]+[^\s<>().,;:!?'"]`)
+
+	strike = regexp.MustCompile(`~~(\S(?:[^\n]*?\S)?)~~`)
+	held   = regexp.MustCompile(holdOpen + `(\d+)` + holdClose)
+
+	// literal troca os marcadores escapados (\*) por sinais parecidos que o
+	// WhatsApp não interpreta; o WhatsApp não tem escape.
+	literal = strings.NewReplacer("*", "∗", "_", "ˍ", "~", "∼", "`", "ˋ")
+)
+
+// FromMarkdown converte o Markdown do assistente para a formatação do WhatsApp:
+// **negrito** e __negrito__ → *negrito*, *itálico* e _itálico_ → _itálico_,
+// ~~riscado~~ → ~riscado~, `código` e blocos ``` mantidos, títulos viram
+// linhas em negrito, tabelas viram listas, links viram "texto (url)" e listas
+// usam "- ". A ênfase segue as regras de delimitadores do CommonMark (ênfase
+// aninhada, snake_case intacto). Não é idempotente: *x* no resultado já é o
+// negrito do WhatsApp e viraria itálico numa segunda passada.
+func FromMarkdown(md string) string {
+	md = strings.ReplaceAll(md, "\r\n", "\n")
+	lines := strings.Split(md, "\n")
+	out := make([]string, 0, len(lines))
+	inFence := false
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if fence.MatchString(line) {
+			// Bloco de código: mantém o conteúdo, sem a linguagem
+			inFence = !inFence
+			out = append(out, "```")
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+		if isTableRow(line) {
+			j := i
+			for j < len(lines) && isTableRow(lines[j]) {
+				j++
+			}
+			out = append(out, flattenTable(lines[i:j])...)
+			i = j - 1
+			continue
+		}
+		// Título setext (texto sublinhado com === ou ---)
+		if i+1 < len(lines) && strings.TrimSpace(line) != "" && !bullet.MatchString(line) && setextRule.MatchString(lines[i+1]) {
+			out = append(out, headingLine(line))
+			i++
+			continue
+		}
+		switch {
+		case heading.MatchString(line):
+			out = append(out, headingLine(heading.FindStringSubmatch(line)[1]))
+		case hrule.MatchString(line):
+			out = append(out, "")
+		case quote.MatchString(line):
+			out = append(out, "> "+inline(quote.ReplaceAllString(line, "")))
+		default:
+			line = bullet.ReplaceAllString(line, "$1- ")
+			out = append(out, inline(line))
+		}
+	}
+	if inFence {
+		out = append(out, "```")
+	}
+	return strings.TrimSpace(collapseBlank(strings.Join(out, "\n")))
+}
+
+func headingLine(text string) string {
+	text = strings.TrimSpace(text)
+	// Ênfase dentro do título é redundante: o título inteiro fica em negrito
+	text = strings.NewReplacer("**", "", "__", "").Replace(text)
+	text = strings.Trim(text, "*")
+	if text == "" {
+		return ""
+	}
+	return inline("**" + text + "**")
+}
+
+// inline converte a ênfase e os links de uma linha, protegendo código inline,
+// URLs e caracteres escapados.
+func inline(s string) string {
+	var hold []string
+	keep := func(v string) string {
+		hold = append(hold, v)
+		return holdOpen + strconv.Itoa(len(hold)-1) + holdClose
+	}
+	s = inlineCode.ReplaceAllStringFunc(s, keep)
+	s = escaped.ReplaceAllStringFunc(s, func(m string) string { return keep(literal.Replace(m[1:])) })
+	s = image.ReplaceAllStringFunc(s, func(m string) string {
+		g := image.FindStringSubmatch(m)
+		return keep(g[2])
+	})
+	s = link.ReplaceAllStringFunc(s, func(m string) string {
+		g := link.FindStringSubmatch(m)
+		text, url := strings.TrimSpace(g[1]), g[2]
+		if sameLink(text, url) {
+			return keep(url)
+		}
+		return text + " (" + keep(url) + ")"
+	})
+	s = autolink.ReplaceAllStringFunc(s, func(m string) string {
+		return keep(strings.TrimPrefix(m[1:len(m)-1], "mailto:"))
+	})
+	s = bareURL.ReplaceAllStringFunc(s, keep)
+
+	s = emphasis(s)
+	s = strike.ReplaceAllString(s, "~$1~")
+	s = strings.NewReplacer(markBold, "*", markItalic, "_").Replace(s)
+
+	return held.ReplaceAllStringFunc(s, func(m string) string {
+		n, _ := strconv.Atoi(m[1 : len(m)-1])
+		return hold[n]
+	})
+}
+
+// delim é uma sequência de * ou _ candidata a abrir/fechar ênfase.
+type delim struct {
+	ch          byte
+	start, end  int // posição da sequência em s
+	orig, n     int // tamanho original e ainda não usado
+	open, close bool
+	// marcadores do WhatsApp emitidos: pre quando a sequência fecha um par
+	// (ficam à esquerda do que sobrou), post quando abre (à direita)
+	pre, post string
+	dead      bool
+}
+
+// emphasis casa as sequências de * e _ como o CommonMark (flanqueamento à
+// esquerda/direita, regra do múltiplo de 3) e troca cada par pelo marcador do
+// WhatsApp: 2 delimitadores → negrito, 1 → itálico. Delimitadores sem par
+// ficam como estão.
+func emphasis(s string) string {
+	var ds []*delim
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c != '*' && c != '_' {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && s[j] == c {
+			j++
+		}
+		before, _ := utf8.DecodeLastRuneInString(s[:i])
+		after, _ := utf8.DecodeRuneInString(s[j:])
+		if i == 0 {
+			before = ' '
+		}
+		if j == len(s) {
+			after = ' '
+		}
+		left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+		right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+		d := &delim{ch: c, start: i, end: j, orig: j - i, n: j - i, open: left, close: right}
+		if c == '_' {
+			// _ dentro de palavra (snake_case) não abre nem fecha
+			d.open = left && (!right || isPunct(before))
+			d.close = right && (!left || isPunct(after))
+		}
+		ds = append(ds, d)
+		i = j
+	}
+	matched := false
+	for ci, c := range ds {
+		for c.close && c.n > 0 {
+			oi := -1
+			for k := ci - 1; k >= 0; k-- {
+				o := ds[k]
+				if o.dead || o.ch != c.ch || !o.open || o.n == 0 {
+					continue
+				}
+				if (o.close || c.open) && (o.orig+c.orig)%3 == 0 && !(o.orig%3 == 0 && c.orig%3 == 0) {
+					continue
+				}
+				oi = k
+				break
+			}
+			if oi < 0 {
+				break
+			}
+			o := ds[oi]
+			use, mark := 1, markItalic
+			if o.n >= 2 && c.n >= 2 {
+				use, mark = 2, markBold
+			}
+			o.n -= use
+			c.n -= use
+			o.post = mark + o.post
+			c.pre += mark
+			matched = true
+			// delimitadores entre o par não podem mais casar por fora dele
+			for k := oi + 1; k < ci; k++ {
+				ds[k].dead = true
+			}
+		}
+	}
+	if !matched {
+		return s
+	}
+	var b strings.Builder
+	last := 0
+	for _, d := range ds {
+		b.WriteString(s[last:d.start])
+		b.WriteString(d.pre + strings.Repeat(string(d.ch), d.n) + d.post)
+		last = d.end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+// isPunct segue a pontuação do CommonMark (inclui símbolos como $ e +); os
+// marcadores de trechos protegidos contam como texto.
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+func sameLink(text, url string) bool {
+	t := strings.TrimPrefix(strings.TrimPrefix(text, "https://"), "http://")
+	u := strings.TrimPrefix(strings.TrimPrefix(url, "https://"), "http://")
+	return strings.TrimSuffix(t, "/") == strings.TrimSuffix(u, "/") || strings.TrimPrefix(url, "mailto:") == text
+}
+
+func isTableRow(line string) bool {
+	t := strings.TrimSpace(line)
+	return strings.HasPrefix(t, "|") && strings.Count(t, "|") >= 2
+}
+
+func tableCells(line string) []string {
+	t := strings.TrimSpace(line)
+	t = strings.TrimSuffix(strings.TrimPrefix(t, "|"), "|")
+	cells := strings.Split(t, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func isSeparator(cells []string) bool {
+	for _, c := range cells {
+		if !tableSep.MatchString(strings.ReplaceAll(c, " ", "")) {
+			return false
+		}
+	}
+	return len(cells) > 0
+}
+
+// flattenTable transforma a tabela em lista: cada linha vira
+// "- *primeira coluna* — Cabeçalho: valor, Cabeçalho: valor".
+func flattenTable(rows []string) []string {
+	var header []string
+	body := rows
+	if len(rows) >= 2 && isSeparator(tableCells(rows[1])) {
+		header = tableCells(rows[0])
+		body = rows[2:]
+	}
+	out := make([]string, 0, len(body))
+	for _, r := range body {
+		cells := tableCells(r)
+		if isSeparator(cells) {
+			continue
+		}
+		var parts []string
+		for i, c := range cells {
+			if c == "" || i == 0 {
+				continue
+			}
+			if i < len(header) && header[i] != "" {
+				parts = append(parts, header[i]+": "+c)
+			} else {
+				parts = append(parts, c)
+			}
+		}
+		first := ""
+		if len(cells) > 0 && cells[0] != "" {
+			first = "**" + strings.Trim(cells[0], "*") + "**"
+		}
+		line := "- " + first
+		if len(parts) > 0 {
+			if first != "" {
+				line += " — "
+			}
+			line += strings.Join(parts, ", ")
+		}
+		out = append(out, inline(line))
+	}
+	return out
+}
+
+// collapseBlank reduz sequências de linhas em branco a uma só.
+func collapseBlank(s string) string {
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	blank := false
+	for _, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			if blank {
+				continue
+			}
+			blank = true
+			out = append(out, "")
+			continue
+		}
+		blank = false
+		out = append(out, strings.TrimRight(l, " \t"))
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,57 @@
+package whatsfmt
+
+import "testing"
+
+func TestFromMarkdown(t *testing.T) {
+	cases := []struct {
+		name, in, want string
+	}{
+		{"negrito", "**bold**", "*bold*"},
+		{"negrito sublinhado", "__bold__", "*bold*"},
+		{"itálico asterisco", "*italic*", "_italic_"},
+		{"itálico sublinhado", "_italic_", "_italic_"},
+		{"negrito e itálico", "***both***", "_*both*_"},
+		{"itálico aninhado", "**bold with *nested* italic**", "*bold with _nested_ italic*"},
+		{"negrito aninhado", "*a **b** c*", "_a *b* c_"},
+		{"dois negritos", "**a** e **b**", "*a* e *b*"},
+		{"negrito com pontuação", "preço: **R$ 10,00**!", "preço: *R$ 10,00*!"},
+		{"sem fechamento", "**não fecha", "**não fecha"},
+		{"multiplicação", "5 * 3 * 2", "5 * 3 * 2"},
+		{"riscado", "~~riscado~~", "~riscado~"},
+		{"asterisco escapado", `a\*b\*`, "a∗b∗"},
+		{"sublinhado escapado", `texto \_literal\_`, "texto ˍliteralˍ"},
+		{"snake_case", "snake_case_name e outro_nome", "snake_case_name e outro_nome"},
+		{"índices", "x_1 + y_2", "x_1 + y_2"},
+		{"título", "# Título", "*Título*"},
+		{"título com fecho", "## Título ##", "*Título*"},
+		{"título já negrito", "## **Já negrito**", "*Já negrito*"},
+		{"título setext", "Título\n===", "*Título*"},
+		{"régua", "a\n\n---\n\nb", "a\n\nb"},
+		{"lista", "* item\n+ outro", "- item\n- outro"},
+		{"citação", "> citação **x**", "> citação *x*"},
+		{"link", "[site](https://x.com)", "site (https://x.com)"},
+		{"link igual ao texto", "[https://x.com](https://x.com)", "https://x.com"},
+		{"autolink", "<https://a.b/c>", "https://a.b/c"},
+		{"imagem", "![img](https://i.png)", "https://i.png"},
+		{"url com sublinhados", "veja https://ex.com/a_b_c_d", "veja https://ex.com/a_b_c_d"},
+		{"url com asteriscos", "**veja** https://ex.com/*x*", "*veja* https://ex.com/*x*"},
+		{"código inline", "use `**raw**` aqui", "use `**raw**` aqui"},
+		{"bloco de código", "```go\nx := **a**\n```", "```\nx := **a**\n```"},
+		{"bloco sem fechamento", "```\ncódigo", "```\ncódigo\n```"},
+		{
+			"tabela",
+			"| Produto | Preço |\n|---|---|\n| Anel | R$ 10 |\n| Brinco | R$ 20 |",
+			"- *Anel* — Preço: R$ 10\n- *Brinco* — Preço: R$ 20",
+		},
+		{"tabela sem cabeçalho", "| Anel | R$ 10 |\n| Brinco | R$ 20 |", "- *Anel* — R$ 10\n- *Brinco* — R$ 20"},
+		{"linhas em branco", "a\n\n\n\nb", "a\n\nb"},
+		{"crlf", "**a**\r\nb", "*a*\nb"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := FromMarkdown(c.in); got != c.want {
+				t.Errorf("FromMarkdown(%q) = %q, want %q", c.in, got, c.want)
+			}
+		})
+	}
+}
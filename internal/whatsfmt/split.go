@@ -0,0 +1,111 @@
+// Package whatsfmt adapta textos gerados pelo assistente ao WhatsApp:
+// divisão em mensagens de tamanho natural e formatação do app.
+package whatsfmt
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxMessage é o limite de caracteres de uma mensagem de texto no WhatsApp.
+const MaxMessage = 4096
+
+var (
+	paragraphs = regexp.MustCompile(`\n\s*\n`)
+	// fim de frase: pontuação seguida de espaço (mantém a pontuação na frase)
+	sentenceEnd = regexp.MustCompile(`[.!?…]+["')\]]*\s+`)
+)
+
+// Split divide o texto em mensagens de até size caracteres, quebrando em
+// parágrafos e, se preciso, em frases; parágrafos curtos seguidos são
+// agrupados. Nenhuma mensagem passa de MaxMessage.
+func Split(text string, size int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if size <= 0 || size > MaxMessage {
+		size = MaxMessage
+	}
+	var pieces []string
+	for _, p := range paragraphs.Split(text, -1) {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if runes(p) <= size {
+			pieces = append(pieces, p)
+			continue
+		}
+		pieces = append(pieces, pack(sentences(p, size), " ", size)...)
+	}
+	return pack(pieces, "\n\n", size)
+}
+
+// pack junta peças consecutivas com sep enquanto couberem em size.
+func pack(pieces []string, sep string, size int) []string {
+	var out []string
+	cur := ""
+	for _, p := range pieces {
+		switch {
+		case cur == "":
+			cur = p
+		case runes(cur)+runes(sep)+runes(p) <= size:
+			cur += sep + p
+		default:
+			out = append(out, cur)
+			cur = p
+		}
+	}
+	if cur != "" {
+		out = append(out, cur)
+	}
+	return out
+}
+
+// sentences quebra um parágrafo em frases; frases maiores que size são
+// cortadas em espaços (ou no meio, se não houver).
+func sentences(p string, size int) []string {
+	var out []string
+	last := 0
+	for _, loc := range sentenceEnd.FindAllStringIndex(p, -1) {
+		out = append(out, strings.TrimSpace(p[last:loc[1]]))
+		last = loc[1]
+	}
+	if rest := strings.TrimSpace(p[last:]); rest != "" {
+		out = append(out, rest)
+	}
+	var fitted []string
+	for _, s := range out {
+		for runes(s) > size {
+			cut := cutAt(s, size)
+			fitted = append(fitted, strings.TrimSpace(s[:cut]))
+			s = strings.TrimSpace(s[cut:])
+		}
+		if s != "" {
+			fitted = append(fitted, s)
+		}
+	}
+	return fitted
+}
+
+// cutAt devolve o índice (em bytes) do último espaço dentro de size runas,
+// ou o limite exato se não houver espaço.
+func cutAt(s string, size int) int {
+	end, n := len(s), 0
+	for i := range s {
+		if n == size {
+			end = i
+			break
+		}
+		n++
+	}
+	if i := strings.LastIndexAny(s[:end], " \n\t"); i > 0 {
+		return i
+	}
+	return end
+}
+
+func runes(s string) int {
+	return utf8.RuneCountInString(s)
+}
@@ -0,0 +1,50 @@
+package whatsfmt
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplit(t *testing.T) {
+	cases := []struct {
+		name string
+		in   string
+		size int
+		want []string
+	}{
+		{"vazio", "  ", 100, nil},
+		{"curto", "Olá! Tudo bem?", 100, []string{"Olá! Tudo bem?"}},
+		{"agrupa parágrafos", "Um.\n\nDois.\n\nTrês.", 12, []string{"Um.\n\nDois.", "Três."}},
+		{"quebra em frases", "Primeira frase. Segunda frase. Terceira.", 20, []string{"Primeira frase.", "Segunda frase.", "Terceira."}},
+		{"frase longa quebra em espaço", "palavra palavra palavra", 10, []string{"palavra", "palavra", "palavra"}},
+		{"sem espaço corta no limite", "abcdefghij", 4, []string{"abcd", "efgh", "ij"}},
+		{"conta runas", "ééééé ççççç", 5, []string{"ééééé", "ççççç"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := Split(c.in, c.size); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("Split(%q, %d) = %q, want %q", c.in, c.size, got, c.want)
+			}
+		})
+	}
+}
+
+func TestSplitMaxMessage(t *testing.T) {
+	long := strings.Repeat("á", 10000)
+	for _, size := range []int{0, -1, MaxMessage, MaxMessage + 1000} {
+		parts := Split(long, size)
+		total := 0
+		for _, p := range parts {
+			n := utf8.RuneCountInString(p)
+			if n > MaxMessage {
+				t.Fatalf("size %d: parte com %d runas (> %d)", size, n, MaxMessage)
+			}
+			total += n
+		}
+		if total != 10000 || len(parts) != 3 {
+			t.Errorf("size %d: %d partes, %d runas", size, len(parts), total)
+		}
+	}
+}